@@ -0,0 +1,22 @@
+-- +migrate Up
+
+create table if not exists team_permissions (
+    team_link text not null,
+    repo_link text not null,
+    access_level text not null,
+    created_at timestamp without time zone not null default current_timestamp,
+    updated_at timestamp with time zone not null default current_timestamp,
+
+    unique (team_link, repo_link),
+    foreign key(team_link) references subs(link) on delete cascade on update cascade
+);
+
+create index if not exists team_permissions_teamlink_idx on team_permissions(team_link);
+create index if not exists team_permissions_repolink_idx on team_permissions(repo_link);
+
+-- +migrate Down
+
+drop index if exists team_permissions_teamlink_idx;
+drop index if exists team_permissions_repolink_idx;
+
+drop table if exists team_permissions;
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+create table if not exists invitations (
+    id serial primary key,
+    invitation_id bigint,
+    request_id text not null,
+    user_id bigint,
+    username text not null,
+    github_id bigint not null,
+    link text not null,
+    type text not null,
+    access_level text not null,
+    state text not null,
+    created_at timestamp without time zone not null default current_timestamp,
+    updated_at timestamp with time zone not null default current_timestamp,
+
+    unique (github_id, link)
+);
+
+create index if not exists invitations_link_idx on invitations(link);
+create index if not exists invitations_state_idx on invitations(state);
+
+-- +migrate Down
+
+drop index if exists invitations_link_idx;
+drop index if exists invitations_state_idx;
+
+drop table if exists invitations;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+alter table invitations alter column github_id drop not null;
+alter table invitations add column if not exists email text;
+alter table invitations add constraint invitations_email_link_key unique (email, link);
+
+-- +migrate Down
+
+alter table invitations drop constraint if exists invitations_email_link_key;
+delete from invitations where github_id is null;
+alter table invitations drop column if exists email;
+alter table invitations alter column github_id set not null;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+alter table permissions add column if not exists collaborator_kind text not null default 'direct';
+
+-- +migrate Down
+
+alter table permissions drop column if exists collaborator_kind;
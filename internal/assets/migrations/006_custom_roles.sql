@@ -0,0 +1,20 @@
+-- +migrate Up
+
+create table if not exists custom_roles (
+    link text not null,
+    name text not null,
+    base_role text not null,
+    description text not null default '',
+    updated_at timestamp with time zone not null default current_timestamp,
+
+    unique (link, name),
+    foreign key(link) references subs(link) on delete cascade on update cascade
+);
+
+create index if not exists custom_roles_link_idx on custom_roles(link);
+
+-- +migrate Down
+
+drop index if exists custom_roles_link_idx;
+
+drop table if exists custom_roles;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+update permissions set access_level = 'read' where type = 'repo' and access_level = 'pull';
+update permissions set access_level = 'write' where type = 'repo' and access_level = 'push';
+
+update invitations set access_level = 'read' where type = 'repo' and access_level = 'pull';
+update invitations set access_level = 'write' where type = 'repo' and access_level = 'push';
+
+-- +migrate Down
+
+-- legacy role names are not restored
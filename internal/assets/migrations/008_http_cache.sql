@@ -0,0 +1,21 @@
+-- +migrate Up
+
+create table if not exists http_cache (
+    url text not null,
+    credential text not null,
+    etag text not null default '',
+    last_modified text not null default '',
+    link text not null default '',
+    body bytea not null,
+    updated_at timestamp with time zone not null default current_timestamp,
+
+    primary key (url, credential)
+);
+
+create index if not exists http_cache_updated_at_idx on http_cache(updated_at);
+
+-- +migrate Down
+
+drop index if exists http_cache_updated_at_idx;
+
+drop table if exists http_cache;
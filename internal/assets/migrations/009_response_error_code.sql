@@ -0,0 +1,7 @@
+-- +migrate Up
+
+alter table responses add column if not exists error_code text not null default '';
+
+-- +migrate Down
+
+alter table responses drop column if exists error_code;
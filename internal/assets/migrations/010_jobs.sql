@@ -0,0 +1,20 @@
+-- +migrate Up
+
+create table if not exists jobs (
+    id uuid primary key,
+    action text not null,
+    payload jsonb not null,
+    status text not null,
+    attempts int not null default 0,
+    error text not null default '',
+    created_at timestamp with time zone not null default current_timestamp,
+    updated_at timestamp with time zone not null default current_timestamp
+);
+
+create index if not exists jobs_status_idx on jobs(status);
+
+-- +migrate Down
+
+drop index if exists jobs_status_idx;
+
+drop table if exists jobs;
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultApiDeadline      = 30 * time.Second
+	defaultReceiverDeadline = 30 * time.Minute
+)
+
+// DeadlinesCfg limits how long requests wait for queued GitHub calls, calls nobody waits for are dropped
+type DeadlinesCfg struct {
+	// Api is deadline of HTTP request
+	Api time.Duration `fig:"api"`
+	// Receiver is deadline of handling one message
+	Receiver time.Duration `fig:"receiver"`
+}
+
+func (c *config) Deadlines() *DeadlinesCfg {
+	return c.deadlines.Do(func() interface{} {
+		cfg := DeadlinesCfg{
+			Api:      defaultApiDeadline,
+			Receiver: defaultReceiverDeadline,
+		}
+		err := figure.
+			Out(&cfg).
+			With(figure.BaseHooks).
+			From(kv.MustGetStringMap(c.getter, "deadlines")).
+			Please()
+
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out deadlines params from config"))
+		}
+
+		return &cfg
+	}).(*DeadlinesCfg)
+}
@@ -1,49 +1,310 @@
 package config
 
 import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/acs-dl/github-module-svc/internal/data"
 	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/golang-jwt/jwt/v4"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
+const (
+	githubWebUrl     = "https://github.com"
+	githubApiUrl     = "https://api.github.com"
+	githubGraphqlUrl = "https://api.github.com/graphql"
+	// GitHub Enterprise Server serves REST API under this path of the instance host
+	enterpriseApiPath = "/api/v3"
+	// GitHub Enterprise Server serves GraphQL API under this path of the instance host
+	enterpriseGraphqlPath = "/api/graphql"
+)
+
+type GithubApiConfig struct {
+	Url            string          `fig:"url"`
+	ApiUrl         string          `fig:"api_url"`
+	ApiVersion     string          `fig:"api_version"`
+	GraphqlUrl     string          `fig:"graphql_url"`
+	DisableGraphql bool            `fig:"disable_graphql"`
+	SkipApiVersion bool            `fig:"skip_api_version"`
+	CaBundle       string          `fig:"ca_bundle"`
+	App            GithubAppConfig `fig:"app"`
+}
+
+type GithubAppConfig struct {
+	Id           int64  `fig:"id"`
+	PrivateKey   string `fig:"private_key"`
+	DefaultOwner string `fig:"default_owner"`
+}
+
+// GithubOwnerConfig overrides credentials and rate limit for single organization or user,
+// owner is authenticated with tokens, installation of its own app or installation of module app
+type GithubOwnerConfig struct {
+	SuperToken     string          `fig:"super_token"`
+	UsualToken     string          `fig:"usual_token"`
+	AppConfig      GithubAppConfig `fig:"app"`
+	RequestsAmount int64           `fig:"requests_amount"`
+	TimeLimit      time.Duration   `fig:"time_limit"`
+
+	// App is set when owner has its own GitHub App installed
+	App *GithubApp `fig:"-"`
+}
+
+// UsesApp tells if owner is authenticated with app installation, its read and write calls spend one budget then
+func (o GithubOwnerConfig) UsesApp() bool {
+	return o.App != nil || o.SuperToken == "" && o.UsualToken == ""
+}
+
+// GithubApp is set when module authenticates as GitHub App instead of personal access tokens
+type GithubApp struct {
+	Id         int64
+	PrivateKey *rsa.PrivateKey
+	// DefaultOwner is the installation account used for calls that aren't bound to any owner
+	DefaultOwner string
+}
+
 type GithubCfg struct {
 	SuperToken string `json:"super_token"`
 	UsualToken string `json:"usual_token"`
+
+	// WebUrl is the root of the GitHub UI, used to build links to submodules
+	WebUrl string
+	// ApiUrl is the root of the REST API all client calls are built from
+	ApiUrl string
+	// GraphqlUrl is GraphQL API endpoint used for bulk queries, empty value means REST API only
+	GraphqlUrl string
+	// ApiVersion is sent as `X-GitHub-Api-Version`, empty value means no header
+	ApiVersion string
+	HttpClient *http.Client
+	App        *GithubApp
+	// Owners maps lowercased owner login to its own credentials
+	Owners map[string]GithubOwnerConfig
 }
 
 func (c *config) Github() *GithubCfg {
 	return c.github.Do(func() interface{} {
 		cfg := lookupConfigEnv()
 
-		err := cfg.validate()
+		apiCfg := GithubApiConfig{
+			Url:        githubWebUrl,
+			ApiVersion: data.GithubApiVersionHeader,
+		}
+
+		raw := kv.MustGetStringMap(c.getter, "github")
+
+		err := figure.
+			Out(&apiCfg).
+			With(figure.BaseHooks).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out github params from config"))
+		}
+
+		cfg.Owners, err = figureOutOwners(raw["owners"])
 		if err != nil {
-			panic(errors.Wrap(err, "failed to validate gitlab params"))
+			panic(errors.Wrap(err, "failed to figure out github owners from config"))
+		}
+
+		cfg.WebUrl = strings.TrimSuffix(apiCfg.Url, "/")
+		cfg.ApiUrl = buildApiUrl(cfg.WebUrl, apiCfg.ApiUrl)
+		if !apiCfg.DisableGraphql {
+			cfg.GraphqlUrl = buildGraphqlUrl(cfg.WebUrl, apiCfg.GraphqlUrl)
+		}
+		if !apiCfg.SkipApiVersion {
+			cfg.ApiVersion = apiCfg.ApiVersion
+		}
+
+		cfg.HttpClient, err = createHttpClient(apiCfg.CaBundle)
+		if err != nil {
+			panic(errors.Wrap(err, "failed to create github http client"))
+		}
+
+		cfg.App, err = createGithubApp(apiCfg.App)
+		if err != nil {
+			panic(errors.Wrap(err, "failed to create github app params"))
+		}
+
+		err = cfg.validate()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to validate github params"))
 		}
 		return cfg
 	}).(*GithubCfg)
 }
 
+// lookupConfigEnv reads personal access tokens, they are optional when GitHub App is configured
 func lookupConfigEnv() *GithubCfg {
-	superToken, ok := os.LookupEnv("super_token")
-	if !ok {
-		panic(errors.New("no super_token env variable"))
+	return &GithubCfg{
+		SuperToken: os.Getenv("super_token"),
+		UsualToken: os.Getenv("usual_token"),
+	}
+}
+
+// buildApiUrl returns explicitly configured api url or derives it from web url:
+// github.com has dedicated api host, Enterprise Server serves api on `/api/v3`
+func buildApiUrl(webUrl, apiUrl string) string {
+	if apiUrl != "" {
+		return strings.TrimSuffix(apiUrl, "/")
+	}
+
+	if webUrl == githubWebUrl {
+		return githubApiUrl
+	}
+
+	return webUrl + enterpriseApiPath
+}
+
+// buildGraphqlUrl returns explicitly configured GraphQL url or derives it from web url like buildApiUrl
+func buildGraphqlUrl(webUrl, graphqlUrl string) string {
+	if graphqlUrl != "" {
+		return strings.TrimSuffix(graphqlUrl, "/")
+	}
+
+	if webUrl == githubWebUrl {
+		return githubGraphqlUrl
+	}
+
+	return webUrl + enterpriseGraphqlPath
+}
+
+func createHttpClient(caBundle string) (*http.Client, error) {
+	if caBundle == "" {
+		return http.DefaultClient, nil
+	}
+
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ca bundle")
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates were found in `%s`", caBundle)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func figureOutOwners(raw interface{}) (map[string]GithubOwnerConfig, error) {
+	owners := make(map[string]GithubOwnerConfig)
+	if raw == nil {
+		return owners, nil
 	}
 
-	usualToken, ok := os.LookupEnv("usual_token")
+	rawOwners, ok := raw.(map[string]interface{})
 	if !ok {
-		panic(errors.New("no usual_token env variable"))
+		return nil, errors.New("owners must be a map of owner login to credentials")
 	}
 
-	return &GithubCfg{
-		superToken,
-		usualToken,
+	for owner, rawOwner := range rawOwners {
+		values, ok := rawOwner.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("credentials of `%s` must be a map", owner)
+		}
+
+		var ownerCfg GithubOwnerConfig
+		err := figure.
+			Out(&ownerCfg).
+			With(figure.BaseHooks).
+			From(values).
+			Please()
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to figure out credentials of `%s`", owner))
+		}
+
+		//installation of owner app is the owner itself
+		if ownerCfg.AppConfig.DefaultOwner == "" {
+			ownerCfg.AppConfig.DefaultOwner = owner
+		}
+		ownerCfg.App, err = createGithubApp(ownerCfg.AppConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to create github app params of `%s`", owner))
+		}
+
+		owners[strings.ToLower(owner)] = ownerCfg
 	}
+
+	return owners, nil
+}
+
+func createGithubApp(cfg GithubAppConfig) (*GithubApp, error) {
+	if cfg.Id == 0 {
+		return nil, nil
+	}
+
+	if cfg.PrivateKey == "" {
+		return nil, errors.New("no private key for github app")
+	}
+
+	pem, err := os.ReadFile(cfg.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read github app private key")
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse github app private key")
+	}
+
+	return &GithubApp{
+		Id:           cfg.Id,
+		PrivateKey:   privateKey,
+		DefaultOwner: strings.ToLower(cfg.DefaultOwner),
+	}, nil
 }
 
 func (g *GithubCfg) validate() error {
+	var tokenRule validation.Rule = validation.Required
+	if g.App != nil {
+		tokenRule = validation.Skip
+	}
+
+	errs := validation.Errors{
+		"super_token": validation.Validate(g.SuperToken, tokenRule),
+		"user_token":  validation.Validate(g.UsualToken, tokenRule),
+		"url":         validation.Validate(g.WebUrl, validation.Required),
+		"api_url":     validation.Validate(g.ApiUrl, validation.Required),
+	}
+
+	for owner, ownerCfg := range g.Owners {
+		errs[fmt.Sprintf("owners/%s", owner)] = ownerCfg.validate(g.App != nil)
+	}
+
+	return errs.Filter()
+}
+
+// validate checks that owner has both tokens or is authenticated with app installation,
+// owner without credentials uses installation of module app
+func (o GithubOwnerConfig) validate(moduleApp bool) error {
+	if o.App != nil {
+		return nil
+	}
+
+	if o.SuperToken == "" && o.UsualToken == "" {
+		if !moduleApp {
+			return errors.New("no tokens and no github app to authenticate owner")
+		}
+		return nil
+	}
+
 	return validation.Errors{
-		"super_token": validation.Validate(g.SuperToken, validation.Required),
-		"user_token":  validation.Validate(g.UsualToken, validation.Required),
+		"super_token": validation.Validate(o.SuperToken, validation.Required),
+		"usual_token": validation.Validate(o.UsualToken, validation.Required),
 	}.Filter()
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type InvitationsCfg struct {
+	// ResendExpired makes worker invite user again when invitation expired without being accepted
+	ResendExpired bool `fig:"resend_expired"`
+}
+
+func (c *config) Invitations() *InvitationsCfg {
+	return c.invitations.Do(func() interface{} {
+		var cfg InvitationsCfg
+		err := figure.
+			Out(&cfg).
+			With(figure.BaseHooks).
+			From(kv.MustGetStringMap(c.getter, "invitations")).
+			Please()
+
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out invitations params from config"))
+		}
+
+		return &cfg
+	}).(*InvitationsCfg)
+}
@@ -24,6 +24,8 @@ type Config interface {
 	Registrator() RegistratorConfig
 	Runners() *RunnersCfg
 	RateLimit() *RateLimitCfg
+	Invitations() *InvitationsCfg
+	Deadlines() *DeadlinesCfg
 }
 
 type config struct {
@@ -43,6 +45,8 @@ type config struct {
 	jwtCfg      comfig.Once
 	runners     comfig.Once
 	rateLimit   comfig.Once
+	invitations comfig.Once
+	deadlines   comfig.Once
 }
 
 func New(getter kv.Getter) Config {
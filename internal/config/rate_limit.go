@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"gitlab.com/distributed_lab/figure"
@@ -8,24 +9,120 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
+const (
+	defaultMaxRetries     = 5
+	defaultBackoffInitial = time.Minute
+	defaultBackoffMax     = time.Hour
+	// defaultHighPriorityReserve is share of budget kept for interactive calls
+	defaultHighPriorityReserve = 0.1
+	// defaultPriorityAging lets low priority call overtake high priority one after 10 minutes of waiting
+	defaultPriorityAging       = time.Minute
+	defaultHighPriorityShare   = 0.5
+	defaultNormalPriorityShare = 0.2
+	defaultLowPriorityShare    = 0.1
+)
+
+// ResourceRateLimitCfg is budget of rate limit resource that isn't shared with core one
+type ResourceRateLimitCfg struct {
+	RequestsAmount int64         `fig:"requests_amount,required"`
+	TimeLimit      time.Duration `fig:"time_limit,required"`
+}
+
+// defaultResources are documented limits of resources that have own budget
+var defaultResources = map[string]ResourceRateLimitCfg{
+	"search":      {RequestsAmount: 30, TimeLimit: time.Minute},
+	"code_search": {RequestsAmount: 10, TimeLimit: time.Minute},
+	"graphql":     {RequestsAmount: 5000, TimeLimit: time.Hour},
+}
+
 type RateLimitCfg struct {
 	RequestsAmount int64         `fig:"requests_amount,required"`
 	TimeLimit      time.Duration `fig:"time_limit,required"`
+	// MaxRetries limits how many times rate limited call is repeated before its error is returned
+	MaxRetries     int           `fig:"max_retries"`
+	BackoffInitial time.Duration `fig:"backoff_initial"`
+	BackoffMax     time.Duration `fig:"backoff_max"`
+	// HighPriorityReserve is share of budget that only high priority calls may spend
+	HighPriorityReserve float64 `fig:"high_priority_reserve"`
+	// Resources maps rate limit resource, e.g. `search`, to its own budget
+	Resources map[string]ResourceRateLimitCfg `fig:"-"`
+	// PriorityAging is wait time that raises priority of queued call by one, zero disables aging
+	PriorityAging time.Duration `fig:"priority_aging"`
+	// HighPriorityShare, NormalPriorityShare and LowPriorityShare are shares of calls guaranteed to priority classes
+	HighPriorityShare   float64 `fig:"high_priority_share"`
+	NormalPriorityShare float64 `fig:"normal_priority_share"`
+	LowPriorityShare    float64 `fig:"low_priority_share"`
 }
 
 func (c *config) RateLimit() *RateLimitCfg {
 	return c.rateLimit.Do(func() interface{} {
-		var cfg RateLimitCfg
+		cfg := RateLimitCfg{
+			MaxRetries:          defaultMaxRetries,
+			BackoffInitial:      defaultBackoffInitial,
+			BackoffMax:          defaultBackoffMax,
+			HighPriorityReserve: defaultHighPriorityReserve,
+			PriorityAging:       defaultPriorityAging,
+			HighPriorityShare:   defaultHighPriorityShare,
+			NormalPriorityShare: defaultNormalPriorityShare,
+			LowPriorityShare:    defaultLowPriorityShare,
+		}
+		raw := kv.MustGetStringMap(c.getter, "rate_limit")
 		err := figure.
 			Out(&cfg).
 			With(figure.BaseHooks).
-			From(kv.MustGetStringMap(c.getter, "rate_limit")).
+			From(raw).
 			Please()
 
 		if err != nil {
 			panic(errors.Wrap(err, "failed to figure out rate limit params from config"))
 		}
 
+		if cfg.HighPriorityShare+cfg.NormalPriorityShare+cfg.LowPriorityShare > 1 {
+			panic(errors.New("shares of priority classes must not exceed 1 in total"))
+		}
+
+		cfg.Resources, err = figureOutResources(raw["resources"])
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out rate limit resources from config"))
+		}
+
 		return &cfg
 	}).(*RateLimitCfg)
 }
+
+func figureOutResources(raw interface{}) (map[string]ResourceRateLimitCfg, error) {
+	resources := make(map[string]ResourceRateLimitCfg)
+	for resource, resourceCfg := range defaultResources {
+		resources[resource] = resourceCfg
+	}
+
+	if raw == nil {
+		return resources, nil
+	}
+
+	rawResources, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("resources must be a map of resource name to its limit")
+	}
+
+	for resource, rawResource := range rawResources {
+		values, ok := rawResource.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("limit of `%s` must be a map", resource)
+		}
+
+		var resourceCfg ResourceRateLimitCfg
+		err := figure.
+			Out(&resourceCfg).
+			With(figure.BaseHooks).
+			From(values).
+			Please()
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to figure out limit of `%s`", resource))
+		}
+
+		resources[resource] = resourceCfg
+	}
+
+	return resources, nil
+}
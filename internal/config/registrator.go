@@ -11,7 +11,7 @@ type RegistratorConfig struct {
 	InnerUrl string `fig:"inner_url,required"`
 	Title    string `fig:"title,required"`
 	Topic    string `fig:"topic,required"`
-	Prefix   string `fig:"prefix,required"`
+	Prefix   string `fig:"prefix"`
 	IsModule bool   `fig:"is_module,required"`
 }
 
@@ -27,6 +27,11 @@ func (c *config) Registrator() RegistratorConfig {
 			panic(errors.Wrap(err, "failed to get core registrator config from config"))
 		}
 
+		//links are built from github instance if prefix isn't set explicitly
+		if cfg.Prefix == "" {
+			cfg.Prefix = c.Github().WebUrl
+		}
+
 		return cfg
 	}).(RegistratorConfig)
 }
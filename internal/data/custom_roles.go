@@ -0,0 +1,25 @@
+package data
+
+import "time"
+
+type CustomRoles interface {
+	New() CustomRoles
+
+	Upsert(role CustomRole) error
+	Delete() error
+	Select() ([]CustomRole, error)
+	Get() (*CustomRole, error)
+
+	FilterByLinks(links ...string) CustomRoles
+	FilterByNames(names ...string) CustomRoles
+	FilterByLowerTime(time time.Time) CustomRoles
+}
+
+// CustomRole is repository role defined by organization, it's cached per organization sub
+type CustomRole struct {
+	Link        string    `json:"-" db:"link" structs:"link"`
+	Name        string    `json:"name" db:"name" structs:"name"`
+	BaseRole    string    `json:"base_role" db:"base_role" structs:"base_role"`
+	Description string    `json:"description" db:"description" structs:"description"`
+	UpdatedAt   time.Time `json:"-" db:"updated_at" structs:"-"`
+}
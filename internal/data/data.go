@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -10,9 +11,9 @@ const (
 	ModuleName             = "github"
 	Organization           = "org"
 	Repository             = "repo"
+	Team                   = "team"
 	UserOwned              = "User"
 	OrganizationOwned      = "Organization"
-	Push                   = "push"
 	AcceptHeader           = "application/vnd.Github+json"
 	GithubApiVersionHeader = "2022-11-28"
 )
@@ -36,6 +37,14 @@ type ModulePayload struct {
 	Username    string   `json:"username"`
 	AccessLevel string   `json:"access_level"`
 	Type        string   `json:"type"`
+	Team        string   `json:"team"`
+	Email       string   `json:"email"`
+}
+
+// Page is one page of paginated list, Next is link of the following page, it is empty for the last one
+type Page[T any] struct {
+	Items []T
+	Next  string
 }
 
 type UnverifiedPayload struct {
@@ -43,23 +52,41 @@ type UnverifiedPayload struct {
 	Users  []UnverifiedUser `json:"users"`
 }
 
-var Roles = map[string]string{
-	"":         "No access",
-	"read":     "Read",
-	"triage":   "Triage",
-	"write":    "Write",
-	"maintain": "Maintain",
-	"admin":    "Admin",
-	"member":   "Member",
-}
-
 type RequestParams struct {
+	// Context cancels request together with call that makes it, nil means background one
+	Context context.Context
 	Method  string
 	Link    string
 	Body    []byte
 	Query   map[string]string
 	Header  map[string]string
 	Timeout time.Duration
+	Client  *http.Client
+	// Cache makes GET request conditional, nil means no caching
+	Cache HttpCache
+	// RateLimit is told about every request made and budget reported in response headers, nil means nobody is interested
+	RateLimit RateLimitObserver
+}
+
+const (
+	// CoreRateLimit is resource of REST API calls that don't have own budget
+	CoreRateLimit    = "core"
+	SearchRateLimit  = "search"
+	GraphqlRateLimit = "graphql"
+)
+
+// RateLimitStatus is rate limit budget of credential for one resource, e.g. `core` or `search`
+type RateLimitStatus struct {
+	Resource  string
+	Limit     int64
+	Remaining int64
+	Reset     time.Time
+}
+
+type RateLimitObserver interface {
+	ObserveRateLimit(status RateLimitStatus)
+	// ObserveRequest charges request of resource, not counted ones, e.g. not modified, are still paced
+	ObserveRequest(resource string, counted bool)
 }
 
 type ResponseParams struct {
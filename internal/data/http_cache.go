@@ -0,0 +1,27 @@
+package data
+
+import "time"
+
+type HttpCache interface {
+	New() HttpCache
+
+	Upsert(response CachedResponse) error
+	Delete() error
+	Get() (*CachedResponse, error)
+
+	FilterByUrls(urls ...string) HttpCache
+	FilterByCredentials(credentials ...string) HttpCache
+	FilterByLowerTime(time time.Time) HttpCache
+}
+
+// CachedResponse is the last full response for url, credential is hash of authorization header
+// because GitHub validators differ for different tokens, link header is kept to paginate replayed response
+type CachedResponse struct {
+	Url          string    `db:"url" structs:"url"`
+	Credential   string    `db:"credential" structs:"credential"`
+	ETag         string    `db:"etag" structs:"etag"`
+	LastModified string    `db:"last_modified" structs:"last_modified"`
+	Link         string    `db:"link" structs:"link"`
+	Body         []byte    `db:"body" structs:"body"`
+	UpdatedAt    time.Time `db:"updated_at" structs:"-"`
+}
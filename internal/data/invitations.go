@@ -0,0 +1,56 @@
+package data
+
+import "time"
+
+const (
+	InvitationPending   = "pending"
+	InvitationAccepted  = "accepted"
+	InvitationExpired   = "expired"
+	InvitationCancelled = "cancelled"
+	// InvitationUnknown is state of email invitation that has gone from GitHub without reported invitee,
+	// it isn't known if it was accepted, so the email can be invited again
+	InvitationUnknown = "unknown"
+)
+
+type Invitations interface {
+	New() Invitations
+
+	Upsert(invitation Invitation) error
+	Update(invitation InvitationToUpdate) error
+	Select() ([]Invitation, error)
+	Get() (*Invitation, error)
+
+	FilterByIds(ids ...int64) Invitations
+	FilterByLinks(links ...string) Invitations
+	FilterByUsernames(usernames ...string) Invitations
+	FilterByEmails(emails ...string) Invitations
+	FilterByGithubIds(githubIds ...int64) Invitations
+	FilterByStates(states ...string) Invitations
+}
+
+// Invitation is access that user gets only after accepting it on GitHub
+type Invitation struct {
+	Id int64 `json:"-" db:"id" structs:"-"`
+	// InvitationId is set for repository invitations, organization ones are managed by membership
+	InvitationId *int64 `json:"id" db:"invitation_id" structs:"invitation_id"`
+	RequestId    string `json:"request_id" db:"request_id" structs:"request_id"`
+	UserId       *int64 `json:"user_id" db:"user_id" structs:"user_id"`
+	Username     string `json:"login" db:"username" structs:"username"`
+	// GithubId and Username are unknown for email invitations until invitee is found on GitHub
+	GithubId    *int64    `json:"github_id" db:"github_id" structs:"github_id"`
+	Email       *string   `json:"email" db:"email" structs:"email"`
+	Link        string    `json:"link" db:"link" structs:"link"`
+	Type        string    `json:"type" db:"type" structs:"type"`
+	AccessLevel string    `json:"access_level" db:"access_level" structs:"access_level"`
+	State       string    `json:"state" db:"state" structs:"state"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" structs:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" structs:"-"`
+}
+
+type InvitationToUpdate struct {
+	InvitationId *int64     `structs:"invitation_id,omitempty"`
+	Username     *string    `structs:"username,omitempty"`
+	GithubId     *int64     `structs:"github_id,omitempty"`
+	State        *string    `structs:"state,omitempty"`
+	UpdatedAt    *time.Time `structs:"updated_at,omitempty"`
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+type Jobs interface {
+	New() Jobs
+
+	Insert(job Job) error
+	Update(job JobToUpdate) error
+	Select() ([]Job, error)
+	Get() (*Job, error)
+	Delete() error
+
+	FilterByIds(ids ...string) Jobs
+	FilterByStatuses(statuses ...string) Jobs
+	FilterByLowerTime(time time.Time) Jobs
+}
+
+// Job is message with mutating action, it is stored before message is acked,
+// so job that wasn't finished before restart is handled again
+type Job struct {
+	Id       string          `json:"id" db:"id" structs:"id"`
+	Action   string          `json:"action" db:"action" structs:"action"`
+	Payload  json.RawMessage `json:"payload" db:"payload" structs:"payload"`
+	Status   string          `json:"status" db:"status" structs:"status"`
+	Attempts int64           `json:"attempts" db:"attempts" structs:"attempts"`
+	// Error is set for failed jobs
+	Error     string    `json:"error" db:"error" structs:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" structs:"-"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" structs:"-"`
+}
+
+type JobToUpdate struct {
+	Status    *string    `structs:"status,omitempty"`
+	Attempts  *int64     `structs:"attempts,omitempty"`
+	Error     *string    `structs:"error,omitempty"`
+	UpdatedAt *time.Time `structs:"updated_at,omitempty"`
+}
@@ -2,6 +2,17 @@ package data
 
 import "time"
 
+const (
+	// CollaboratorMember has access as organization member, e.g. by base permissions
+	CollaboratorMember = "member"
+	// CollaboratorOutside isn't organization member, but has access to its repositories
+	CollaboratorOutside = "outside"
+	// CollaboratorDirect is organization member granted access to repository itself
+	CollaboratorDirect = "direct"
+	// CollaboratorTeam is organization member with access through team
+	CollaboratorTeam = "team"
+)
+
 type Permissions interface {
 	New() Permissions
 
@@ -15,6 +26,7 @@ type Permissions interface {
 	FilterByUsernames(usernames ...string) Permissions
 	FilterByLinks(links ...string) Permissions
 	FilterByTypes(types ...string) Permissions
+	FilterByAccessLevels(accessLevels ...string) Permissions
 	FilterByGreaterTime(time time.Time) Permissions
 	FilterByLowerTime(time time.Time) Permissions
 	FilterByParentLinks(parentLinks ...string) Permissions
@@ -35,15 +47,20 @@ type Permission struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at" structs:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" structs:"-"`
 	ExpiresAt   time.Time `json:"expires_at" db:"expires_at" structs:"expires_at"`
-	AvatarUrl   string    `json:"avatar_url" db:"-" structs:"-"`
+	// CollaboratorKind tells how user got access, empty value is stored as direct
+	CollaboratorKind string `json:"-" db:"collaborator_kind" structs:"collaborator_kind,omitempty"`
+	AvatarUrl        string `json:"avatar_url" db:"-" structs:"-"`
+	// Invitation is set when user has to accept invitation before getting access
+	Invitation *Invitation `json:"-" db:"-" structs:"-"`
 }
 
 type PermissionToUpdate struct {
-	Username    *string    `structs:"username,omitempty"`
-	AccessLevel *string    `structs:"access_level,omitempty"`
-	UserId      *int64     `structs:"user_id,omitempty"`
-	ParentLink  *string    `structs:"parent_link,omitempty"`
-	HasParent   *bool      `structs:"has_parent,omitempty"`
-	HasChild    *bool      `structs:"has_child,omitempty"`
-	UpdatedAt   *time.Time `structs:"updated_at,omitempty"`
+	Username         *string    `structs:"username,omitempty"`
+	AccessLevel      *string    `structs:"access_level,omitempty"`
+	UserId           *int64     `structs:"user_id,omitempty"`
+	ParentLink       *string    `structs:"parent_link,omitempty"`
+	HasParent        *bool      `structs:"has_parent,omitempty"`
+	HasChild         *bool      `structs:"has_child,omitempty"`
+	UpdatedAt        *time.Time `structs:"updated_at,omitempty"`
+	CollaboratorKind *string    `structs:"collaborator_kind,omitempty"`
 }
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/fatih/structs"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	customRolesTableName         = "custom_roles"
+	customRolesLinkColumn        = customRolesTableName + ".link"
+	customRolesNameColumn        = customRolesTableName + ".name"
+	customRolesBaseRoleColumn    = customRolesTableName + ".base_role"
+	customRolesDescriptionColumn = customRolesTableName + ".description"
+	customRolesUpdatedAtColumn   = customRolesTableName + ".updated_at"
+)
+
+type CustomRolesQ struct {
+	db            *pgdb.DB
+	selectBuilder sq.SelectBuilder
+	deleteBuilder sq.DeleteBuilder
+}
+
+var customRolesColumns = []string{
+	customRolesLinkColumn,
+	customRolesNameColumn,
+	customRolesBaseRoleColumn,
+	customRolesDescriptionColumn,
+	customRolesUpdatedAtColumn,
+}
+
+func NewCustomRolesQ(db *pgdb.DB) data.CustomRoles {
+	return &CustomRolesQ{
+		db:            db.Clone(),
+		selectBuilder: sq.Select(customRolesColumns...).From(customRolesTableName),
+		deleteBuilder: sq.Delete(customRolesTableName),
+	}
+}
+
+func (q CustomRolesQ) New() data.CustomRoles {
+	return NewCustomRolesQ(q.db)
+}
+
+func (q CustomRolesQ) Upsert(role data.CustomRole) error {
+	updateStmt, args := sq.Update(" ").
+		Set("updated_at", time.Now()).
+		Set("base_role", role.BaseRole).
+		Set("description", role.Description).MustSql()
+
+	query := sq.Insert(customRolesTableName).SetMap(structs.Map(role)).
+		Suffix("ON CONFLICT (link, name) DO "+updateStmt, args...)
+
+	return q.db.Exec(query)
+}
+
+func (q CustomRolesQ) Select() ([]data.CustomRole, error) {
+	var result []data.CustomRole
+
+	err := q.db.Select(&result, q.selectBuilder)
+
+	return result, err
+}
+
+func (q CustomRolesQ) Get() (*data.CustomRole, error) {
+	var result data.CustomRole
+
+	err := q.db.Get(&result, q.selectBuilder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &result, err
+}
+
+func (q CustomRolesQ) Delete() error {
+	var deleted []data.CustomRole
+
+	err := q.db.Select(&deleted, q.deleteBuilder.Suffix("RETURNING *"))
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		return errors.Errorf("no such data to delete")
+	}
+
+	return nil
+}
+
+func (q CustomRolesQ) FilterByLinks(links ...string) data.CustomRoles {
+	equalLinks := sq.Eq{customRolesLinkColumn: links}
+
+	q.selectBuilder = q.selectBuilder.Where(equalLinks)
+	q.deleteBuilder = q.deleteBuilder.Where(equalLinks)
+
+	return q
+}
+
+func (q CustomRolesQ) FilterByNames(names ...string) data.CustomRoles {
+	equalNames := sq.Eq{customRolesNameColumn: names}
+
+	q.selectBuilder = q.selectBuilder.Where(equalNames)
+	q.deleteBuilder = q.deleteBuilder.Where(equalNames)
+
+	return q
+}
+
+func (q CustomRolesQ) FilterByLowerTime(time time.Time) data.CustomRoles {
+	lowerTime := sq.Lt{customRolesUpdatedAtColumn: time}
+
+	q.selectBuilder = q.selectBuilder.Where(lowerTime)
+	q.deleteBuilder = q.deleteBuilder.Where(lowerTime)
+
+	return q
+}
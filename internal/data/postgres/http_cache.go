@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/fatih/structs"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	httpCacheTableName        = "http_cache"
+	httpCacheUrlColumn        = httpCacheTableName + ".url"
+	httpCacheCredentialColumn = httpCacheTableName + ".credential"
+	httpCacheUpdatedAtColumn  = httpCacheTableName + ".updated_at"
+)
+
+type HttpCacheQ struct {
+	db            *pgdb.DB
+	selectBuilder sq.SelectBuilder
+	deleteBuilder sq.DeleteBuilder
+}
+
+func NewHttpCacheQ(db *pgdb.DB) data.HttpCache {
+	return &HttpCacheQ{
+		db:            db.Clone(),
+		selectBuilder: sq.Select("*").From(httpCacheTableName),
+		deleteBuilder: sq.Delete(httpCacheTableName),
+	}
+}
+
+func (q HttpCacheQ) New() data.HttpCache {
+	return NewHttpCacheQ(q.db)
+}
+
+func (q HttpCacheQ) Upsert(response data.CachedResponse) error {
+	updateStmt, args := sq.Update(" ").
+		Set("etag", response.ETag).
+		Set("last_modified", response.LastModified).
+		Set("link", response.Link).
+		Set("body", response.Body).
+		Set("updated_at", time.Now()).MustSql()
+
+	query := sq.Insert(httpCacheTableName).SetMap(structs.Map(response)).
+		Suffix("ON CONFLICT (url, credential) DO "+updateStmt, args...)
+
+	return q.db.Exec(query)
+}
+
+// Delete doesn't fail when nothing was deleted, cache can be empty
+func (q HttpCacheQ) Delete() error {
+	return q.db.Exec(q.deleteBuilder)
+}
+
+func (q HttpCacheQ) Get() (*data.CachedResponse, error) {
+	var result data.CachedResponse
+
+	err := q.db.Get(&result, q.selectBuilder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &result, err
+}
+
+func (q HttpCacheQ) FilterByUrls(urls ...string) data.HttpCache {
+	equalUrls := sq.Eq{httpCacheUrlColumn: urls}
+
+	q.selectBuilder = q.selectBuilder.Where(equalUrls)
+	q.deleteBuilder = q.deleteBuilder.Where(equalUrls)
+
+	return q
+}
+
+func (q HttpCacheQ) FilterByCredentials(credentials ...string) data.HttpCache {
+	equalCredentials := sq.Eq{httpCacheCredentialColumn: credentials}
+
+	q.selectBuilder = q.selectBuilder.Where(equalCredentials)
+	q.deleteBuilder = q.deleteBuilder.Where(equalCredentials)
+
+	return q
+}
+
+func (q HttpCacheQ) FilterByLowerTime(time time.Time) data.HttpCache {
+	lowerTime := sq.Lt{httpCacheUpdatedAtColumn: time}
+
+	q.selectBuilder = q.selectBuilder.Where(lowerTime)
+	q.deleteBuilder = q.deleteBuilder.Where(lowerTime)
+
+	return q
+}
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/fatih/structs"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	invitationsTableName          = "invitations"
+	invitationsIdColumn           = invitationsTableName + ".id"
+	invitationsInvitationIdColumn = invitationsTableName + ".invitation_id"
+	invitationsRequestIdColumn    = invitationsTableName + ".request_id"
+	invitationsUserIdColumn       = invitationsTableName + ".user_id"
+	invitationsUsernameColumn     = invitationsTableName + ".username"
+	invitationsGithubIdColumn     = invitationsTableName + ".github_id"
+	invitationsEmailColumn        = invitationsTableName + ".email"
+	invitationsLinkColumn         = invitationsTableName + ".link"
+	invitationsTypeColumn         = invitationsTableName + ".type"
+	invitationsAccessLevelColumn  = invitationsTableName + ".access_level"
+	invitationsStateColumn        = invitationsTableName + ".state"
+	invitationsCreatedAtColumn    = invitationsTableName + ".created_at"
+	invitationsUpdatedAtColumn    = invitationsTableName + ".updated_at"
+)
+
+type InvitationsQ struct {
+	db            *pgdb.DB
+	selectBuilder sq.SelectBuilder
+	updateBuilder sq.UpdateBuilder
+}
+
+var invitationsColumns = []string{
+	invitationsIdColumn,
+	invitationsInvitationIdColumn,
+	invitationsRequestIdColumn,
+	invitationsUserIdColumn,
+	invitationsUsernameColumn,
+	invitationsGithubIdColumn,
+	invitationsEmailColumn,
+	invitationsLinkColumn,
+	invitationsTypeColumn,
+	invitationsAccessLevelColumn,
+	invitationsStateColumn,
+	invitationsCreatedAtColumn,
+	invitationsUpdatedAtColumn,
+}
+
+func NewInvitationsQ(db *pgdb.DB) data.Invitations {
+	return &InvitationsQ{
+		db:            db.Clone(),
+		selectBuilder: sq.Select(invitationsColumns...).From(invitationsTableName),
+		updateBuilder: sq.Update(invitationsTableName),
+	}
+}
+
+func (q InvitationsQ) New() data.Invitations {
+	return NewInvitationsQ(q.db)
+}
+
+func (q InvitationsQ) Upsert(invitation data.Invitation) error {
+	updateStmt, args := sq.Update(" ").
+		Set("updated_at", time.Now()).
+		Set("invitation_id", invitation.InvitationId).
+		Set("request_id", invitation.RequestId).
+		Set("user_id", invitation.UserId).
+		Set("access_level", invitation.AccessLevel).
+		Set("state", invitation.State).MustSql()
+
+	//email invitations have no github account yet
+	conflictColumns := "github_id, link"
+	if invitation.GithubId == nil {
+		conflictColumns = "email, link"
+	}
+
+	query := sq.Insert(invitationsTableName).SetMap(structs.Map(invitation)).
+		Suffix("ON CONFLICT ("+conflictColumns+") DO "+updateStmt, args...)
+
+	return q.db.Exec(query)
+}
+
+func (q InvitationsQ) Update(invitation data.InvitationToUpdate) error {
+	updatedAt := time.Now()
+	invitation.UpdatedAt = &updatedAt
+
+	q.updateBuilder = q.updateBuilder.SetMap(structs.Map(invitation))
+
+	return q.db.Exec(q.updateBuilder)
+}
+
+func (q InvitationsQ) Select() ([]data.Invitation, error) {
+	var result []data.Invitation
+
+	err := q.db.Select(&result, q.selectBuilder)
+
+	return result, err
+}
+
+func (q InvitationsQ) Get() (*data.Invitation, error) {
+	var result data.Invitation
+
+	err := q.db.Get(&result, q.selectBuilder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &result, err
+}
+
+func (q InvitationsQ) FilterByIds(ids ...int64) data.Invitations {
+	equalIds := sq.Eq{invitationsIdColumn: ids}
+
+	q.selectBuilder = q.selectBuilder.Where(equalIds)
+	q.updateBuilder = q.updateBuilder.Where(equalIds)
+
+	return q
+}
+
+func (q InvitationsQ) FilterByLinks(links ...string) data.Invitations {
+	equalLinks := sq.Eq{invitationsLinkColumn: links}
+
+	q.selectBuilder = q.selectBuilder.Where(equalLinks)
+	q.updateBuilder = q.updateBuilder.Where(equalLinks)
+
+	return q
+}
+
+func (q InvitationsQ) FilterByUsernames(usernames ...string) data.Invitations {
+	equalUsernames := sq.Eq{invitationsUsernameColumn: usernames}
+
+	q.selectBuilder = q.selectBuilder.Where(equalUsernames)
+	q.updateBuilder = q.updateBuilder.Where(equalUsernames)
+
+	return q
+}
+
+func (q InvitationsQ) FilterByEmails(emails ...string) data.Invitations {
+	equalEmails := sq.Eq{invitationsEmailColumn: emails}
+
+	q.selectBuilder = q.selectBuilder.Where(equalEmails)
+	q.updateBuilder = q.updateBuilder.Where(equalEmails)
+
+	return q
+}
+
+func (q InvitationsQ) FilterByGithubIds(githubIds ...int64) data.Invitations {
+	equalGithubIds := sq.Eq{invitationsGithubIdColumn: githubIds}
+
+	q.selectBuilder = q.selectBuilder.Where(equalGithubIds)
+	q.updateBuilder = q.updateBuilder.Where(equalGithubIds)
+
+	return q
+}
+
+func (q InvitationsQ) FilterByStates(states ...string) data.Invitations {
+	equalStates := sq.Eq{invitationsStateColumn: states}
+
+	q.selectBuilder = q.selectBuilder.Where(equalStates)
+	q.updateBuilder = q.updateBuilder.Where(equalStates)
+
+	return q
+}
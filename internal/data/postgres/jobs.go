@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/fatih/structs"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	jobsTableName       = "jobs"
+	jobsIdColumn        = jobsTableName + ".id"
+	jobsStatusColumn    = jobsTableName + ".status"
+	jobsCreatedAtColumn = jobsTableName + ".created_at"
+	jobsUpdatedAtColumn = jobsTableName + ".updated_at"
+)
+
+type JobsQ struct {
+	db            *pgdb.DB
+	selectBuilder sq.SelectBuilder
+	updateBuilder sq.UpdateBuilder
+	deleteBuilder sq.DeleteBuilder
+}
+
+func NewJobsQ(db *pgdb.DB) data.Jobs {
+	return &JobsQ{
+		db:            db.Clone(),
+		selectBuilder: sq.Select("*").From(jobsTableName).OrderBy(jobsCreatedAtColumn),
+		updateBuilder: sq.Update(jobsTableName),
+		deleteBuilder: sq.Delete(jobsTableName),
+	}
+}
+
+func (q JobsQ) New() data.Jobs {
+	return NewJobsQ(q.db)
+}
+
+// Insert doesn't change job that exists already, redelivered message mustn't reset its state
+func (q JobsQ) Insert(job data.Job) error {
+	query := sq.Insert(jobsTableName).SetMap(structs.Map(job)).Suffix("ON CONFLICT (id) DO NOTHING")
+
+	return q.db.Exec(query)
+}
+
+func (q JobsQ) Update(job data.JobToUpdate) error {
+	updatedAt := time.Now()
+	job.UpdatedAt = &updatedAt
+
+	q.updateBuilder = q.updateBuilder.SetMap(structs.Map(job))
+
+	return q.db.Exec(q.updateBuilder)
+}
+
+func (q JobsQ) Select() ([]data.Job, error) {
+	var result []data.Job
+
+	err := q.db.Select(&result, q.selectBuilder)
+
+	return result, err
+}
+
+func (q JobsQ) Get() (*data.Job, error) {
+	var result data.Job
+
+	err := q.db.Get(&result, q.selectBuilder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &result, err
+}
+
+// Delete doesn't fail when nothing was deleted, there can be no finished jobs
+func (q JobsQ) Delete() error {
+	return q.db.Exec(q.deleteBuilder)
+}
+
+func (q JobsQ) FilterByIds(ids ...string) data.Jobs {
+	equalIds := sq.Eq{jobsIdColumn: ids}
+
+	q.selectBuilder = q.selectBuilder.Where(equalIds)
+	q.updateBuilder = q.updateBuilder.Where(equalIds)
+	q.deleteBuilder = q.deleteBuilder.Where(equalIds)
+
+	return q
+}
+
+func (q JobsQ) FilterByStatuses(statuses ...string) data.Jobs {
+	equalStatuses := sq.Eq{jobsStatusColumn: statuses}
+
+	q.selectBuilder = q.selectBuilder.Where(equalStatuses)
+	q.updateBuilder = q.updateBuilder.Where(equalStatuses)
+	q.deleteBuilder = q.deleteBuilder.Where(equalStatuses)
+
+	return q
+}
+
+func (q JobsQ) FilterByLowerTime(time time.Time) data.Jobs {
+	lowerTime := sq.Lt{jobsUpdatedAtColumn: time}
+
+	q.selectBuilder = q.selectBuilder.Where(lowerTime)
+	q.updateBuilder = q.updateBuilder.Where(lowerTime)
+	q.deleteBuilder = q.deleteBuilder.Where(lowerTime)
+
+	return q
+}
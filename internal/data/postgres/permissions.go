@@ -12,20 +12,21 @@ import (
 )
 
 const (
-	permissionsTableName         = "permissions"
-	permissionsRequestIdColumn   = permissionsTableName + ".request_id"
-	permissionsUserIdColumn      = permissionsTableName + ".user_id"
-	permissionsUsernameColumn    = permissionsTableName + ".username"
-	permissionsGithubIdColumn    = permissionsTableName + ".github_id"
-	permissionsLinkColumn        = permissionsTableName + ".link"
-	permissionsAccessLevelColumn = permissionsTableName + ".access_level"
-	permissionsTypeColumn        = permissionsTableName + ".type"
-	permissionsCreatedAtColumn   = permissionsTableName + ".created_at"
-	permissionsExpiresAtColumn   = permissionsTableName + ".expires_at"
-	permissionsUpdatedAtColumn   = permissionsTableName + ".updated_at"
-	permissionsParentLinkColumn  = permissionsTableName + ".parent_link"
-	permissionsHasParentColumn   = permissionsTableName + ".has_parent"
-	permissionsHasChildColumn    = permissionsTableName + ".has_child"
+	permissionsTableName              = "permissions"
+	permissionsRequestIdColumn        = permissionsTableName + ".request_id"
+	permissionsUserIdColumn           = permissionsTableName + ".user_id"
+	permissionsUsernameColumn         = permissionsTableName + ".username"
+	permissionsGithubIdColumn         = permissionsTableName + ".github_id"
+	permissionsLinkColumn             = permissionsTableName + ".link"
+	permissionsAccessLevelColumn      = permissionsTableName + ".access_level"
+	permissionsTypeColumn             = permissionsTableName + ".type"
+	permissionsCreatedAtColumn        = permissionsTableName + ".created_at"
+	permissionsExpiresAtColumn        = permissionsTableName + ".expires_at"
+	permissionsUpdatedAtColumn        = permissionsTableName + ".updated_at"
+	permissionsParentLinkColumn       = permissionsTableName + ".parent_link"
+	permissionsHasParentColumn        = permissionsTableName + ".has_parent"
+	permissionsHasChildColumn         = permissionsTableName + ".has_child"
+	permissionsCollaboratorKindColumn = permissionsTableName + ".collaborator_kind"
 )
 
 type PermissionsQ struct {
@@ -49,6 +50,7 @@ var permissionsColumns = []string{
 	permissionsHasParentColumn,
 	permissionsHasChildColumn,
 	permissionsParentLinkColumn,
+	permissionsCollaboratorKindColumn,
 }
 
 func NewPermissionsQ(db *pgdb.DB) data.Permissions {
@@ -82,10 +84,16 @@ func (q PermissionsQ) Select() ([]data.Permission, error) {
 }
 
 func (q PermissionsQ) Upsert(permission data.Permission) error {
-	updateStmt, args := sq.Update(" ").
+	updateQuery := sq.Update(" ").
 		Set("updated_at", time.Now()).
 		Set("username", permission.Username).
-		Set("access_level", permission.AccessLevel).MustSql()
+		Set("access_level", permission.AccessLevel)
+
+	if permission.CollaboratorKind != "" {
+		updateQuery = updateQuery.Set("collaborator_kind", permission.CollaboratorKind)
+	}
+
+	updateStmt, args := updateQuery.MustSql()
 
 	query := sq.Insert(permissionsTableName).SetMap(structs.Map(permission)).
 		Suffix("ON CONFLICT (github_id, link) DO "+updateStmt, args...)
@@ -159,6 +167,16 @@ func (q PermissionsQ) FilterByTypes(types ...string) data.Permissions {
 	return q
 }
 
+func (q PermissionsQ) FilterByAccessLevels(accessLevels ...string) data.Permissions {
+	equalAccessLevels := sq.Eq{permissionsAccessLevelColumn: accessLevels}
+
+	q.selectBuilder = q.selectBuilder.Where(equalAccessLevels)
+	q.deleteBuilder = q.deleteBuilder.Where(equalAccessLevels)
+	q.updateBuilder = q.updateBuilder.Where(equalAccessLevels)
+
+	return q
+}
+
 func (q PermissionsQ) FilterByParentLinks(parentLinks ...string) data.Permissions {
 	equalParentLinks := sq.Eq{permissionsParentLinkColumn: parentLinks}
 	if len(parentLinks) == 0 {
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/fatih/structs"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	teamPermissionsTableName         = "team_permissions"
+	teamPermissionsTeamLinkColumn    = teamPermissionsTableName + ".team_link"
+	teamPermissionsRepoLinkColumn    = teamPermissionsTableName + ".repo_link"
+	teamPermissionsAccessLevelColumn = teamPermissionsTableName + ".access_level"
+	teamPermissionsCreatedAtColumn   = teamPermissionsTableName + ".created_at"
+	teamPermissionsUpdatedAtColumn   = teamPermissionsTableName + ".updated_at"
+)
+
+type TeamPermissionsQ struct {
+	db            *pgdb.DB
+	selectBuilder sq.SelectBuilder
+	deleteBuilder sq.DeleteBuilder
+}
+
+var teamPermissionsColumns = []string{
+	teamPermissionsTeamLinkColumn,
+	teamPermissionsRepoLinkColumn,
+	teamPermissionsAccessLevelColumn,
+	teamPermissionsCreatedAtColumn,
+	teamPermissionsUpdatedAtColumn,
+}
+
+func NewTeamPermissionsQ(db *pgdb.DB) data.TeamPermissions {
+	return &TeamPermissionsQ{
+		db:            db.Clone(),
+		selectBuilder: sq.Select(teamPermissionsColumns...).From(teamPermissionsTableName),
+		deleteBuilder: sq.Delete(teamPermissionsTableName),
+	}
+}
+
+func (q TeamPermissionsQ) New() data.TeamPermissions {
+	return NewTeamPermissionsQ(q.db)
+}
+
+func (q TeamPermissionsQ) Upsert(permission data.TeamPermission) error {
+	updateStmt, args := sq.Update(" ").
+		Set("updated_at", time.Now()).
+		Set("access_level", permission.AccessLevel).MustSql()
+
+	query := sq.Insert(teamPermissionsTableName).SetMap(structs.Map(permission)).
+		Suffix("ON CONFLICT (team_link, repo_link) DO "+updateStmt, args...)
+
+	return q.db.Exec(query)
+}
+
+func (q TeamPermissionsQ) Select() ([]data.TeamPermission, error) {
+	var result []data.TeamPermission
+
+	err := q.db.Select(&result, q.selectBuilder)
+
+	return result, err
+}
+
+func (q TeamPermissionsQ) Get() (*data.TeamPermission, error) {
+	var result data.TeamPermission
+
+	err := q.db.Get(&result, q.selectBuilder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &result, err
+}
+
+func (q TeamPermissionsQ) Delete() error {
+	var deleted []data.TeamPermission
+
+	err := q.db.Select(&deleted, q.deleteBuilder.Suffix("RETURNING *"))
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		return errors.Errorf("no such data to delete")
+	}
+
+	return nil
+}
+
+func (q TeamPermissionsQ) FilterByTeamLinks(teamLinks ...string) data.TeamPermissions {
+	equalTeamLinks := sq.Eq{teamPermissionsTeamLinkColumn: teamLinks}
+
+	q.selectBuilder = q.selectBuilder.Where(equalTeamLinks)
+	q.deleteBuilder = q.deleteBuilder.Where(equalTeamLinks)
+
+	return q
+}
+
+func (q TeamPermissionsQ) FilterByRepoLinks(repoLinks ...string) data.TeamPermissions {
+	equalRepoLinks := sq.Eq{teamPermissionsRepoLinkColumn: repoLinks}
+
+	q.selectBuilder = q.selectBuilder.Where(equalRepoLinks)
+	q.deleteBuilder = q.deleteBuilder.Where(equalRepoLinks)
+
+	return q
+}
+
+func (q TeamPermissionsQ) FilterByGithubIds(githubIds ...int64) data.TeamPermissions {
+	return q.filterByMembers(sq.Eq{permissionsGithubIdColumn: githubIds})
+}
+
+func (q TeamPermissionsQ) FilterByUserIds(userIds ...int64) data.TeamPermissions {
+	return q.filterByMembers(sq.Eq{permissionsUserIdColumn: userIds})
+}
+
+func (q TeamPermissionsQ) FilterByUsernames(usernames ...string) data.TeamPermissions {
+	return q.filterByMembers(sq.Eq{permissionsUsernameColumn: usernames})
+}
+
+// filterByMembers keeps grants of teams having member that matches condition
+func (q TeamPermissionsQ) filterByMembers(condition sq.Sqlizer) data.TeamPermissions {
+	membersSql, args := sq.Select(permissionsLinkColumn).
+		From(permissionsTableName).
+		Where(sq.Eq{permissionsTypeColumn: data.Team}).
+		Where(condition).MustSql()
+
+	inTeams := sq.Expr(teamPermissionsTeamLinkColumn+" IN ("+membersSql+")", args...)
+
+	q.selectBuilder = q.selectBuilder.Where(inTeams)
+	q.deleteBuilder = q.deleteBuilder.Where(inTeams)
+
+	return q
+}
+
+func (q TeamPermissionsQ) FilterByLowerTime(time time.Time) data.TeamPermissions {
+	lowerTime := sq.Lt{teamPermissionsUpdatedAtColumn: time}
+
+	q.selectBuilder = q.selectBuilder.Where(lowerTime)
+	q.deleteBuilder = q.deleteBuilder.Where(lowerTime)
+
+	return q
+}
@@ -17,6 +17,7 @@ type Response struct {
 	ID        string          `json:"id" db:"id" structs:"id"`
 	Status    string          `json:"status" db:"status" structs:"status"`
 	Error     string          `json:"error" db:"error" structs:"error"`
+	ErrorCode string          `json:"error_code" db:"error_code" structs:"error_code"`
 	Payload   json.RawMessage `json:"payload" db:"payload" structs:"payload"`
 	CreatedAt string          `json:"created_at" db:"created_at" structs:"-"`
 }
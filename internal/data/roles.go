@@ -0,0 +1,167 @@
+package data
+
+const (
+	RoleRead       = "read"
+	RoleTriage     = "triage"
+	RoleWrite      = "write"
+	RoleMaintain   = "maintain"
+	RoleAdmin      = "admin"
+	RoleMember     = "member"
+	RoleMaintainer = "maintainer"
+
+	// BillingManager manages organization billing settings, it doesn't require membership
+	BillingManager = "billing_manager"
+	// SecurityManager is organization member who manages security alerts and settings
+	SecurityManager = "security_manager"
+	// Moderator is organization member who can block users and hide comments
+	Moderator = "moderator"
+
+	// Pull and Push are legacy names of repository roles, GitHub still returns them in some responses
+	Pull = "pull"
+	Push = "push"
+)
+
+// ModuleOwnerName is jwt name of module role that operates module itself, e.g. its queues,
+// it isn't shared with submodule roles, so repository or organization admins don't get it
+const ModuleOwnerName = "Owner"
+
+// Role is access level in scope of submodule type, roles of one scope are ordered
+type Role struct {
+	Scope string
+	Value string
+	Name  string
+	level int
+}
+
+// AtLeast tells if role grants at least the same access as other one, roles of different scopes aren't comparable
+func (r Role) AtLeast(other Role) bool {
+	return r.Scope == other.Scope && r.level >= other.level
+}
+
+var scopeRoles = map[string][]Role{
+	Organization: {
+		{Scope: Organization, Value: BillingManager, Name: "Billing manager", level: 0},
+		{Scope: Organization, Value: RoleMember, Name: "Member", level: 1},
+		{Scope: Organization, Value: Moderator, Name: "Moderator", level: 2},
+		{Scope: Organization, Value: SecurityManager, Name: "Security manager", level: 3},
+		{Scope: Organization, Value: RoleAdmin, Name: "Admin", level: 4},
+	},
+	Repository: {
+		{Scope: Repository, Value: RoleRead, Name: "Read", level: 0},
+		{Scope: Repository, Value: RoleTriage, Name: "Triage", level: 1},
+		{Scope: Repository, Value: RoleWrite, Name: "Write", level: 2},
+		{Scope: Repository, Value: RoleMaintain, Name: "Maintain", level: 3},
+		{Scope: Repository, Value: RoleAdmin, Name: "Admin", level: 4},
+	},
+	Team: {
+		{Scope: Team, Value: RoleMember, Name: "Member", level: 0},
+		{Scope: Team, Value: RoleMaintainer, Name: "Maintainer", level: 1},
+	},
+}
+
+var legacyRoles = map[string]string{
+	Pull: RoleRead,
+	Push: RoleWrite,
+}
+
+const noAccessName = "No access"
+
+// OrganizationRoles are assigned apart from membership role, in order of precedence
+var OrganizationRoles = []string{SecurityManager, Moderator, BillingManager}
+
+func IsOrganizationRole(accessLevel string) bool {
+	for _, role := range OrganizationRoles {
+		if role == accessLevel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizeRole replaces legacy role names with current ones
+func NormalizeRole(accessLevel string) string {
+	if role, ok := legacyRoles[accessLevel]; ok {
+		return role
+	}
+
+	return accessLevel
+}
+
+// RolesOf returns roles of scope from the lowest to the highest
+func RolesOf(scope string) []Role {
+	return append([]Role(nil), scopeRoles[scope]...)
+}
+
+// FindRole looks for role in scope, legacy names are accepted
+func FindRole(scope, accessLevel string) (Role, bool) {
+	accessLevel = NormalizeRole(accessLevel)
+
+	for _, role := range scopeRoles[scope] {
+		if role.Value == accessLevel {
+			return role, true
+		}
+	}
+
+	return Role{}, false
+}
+
+// RolesAtLeast returns roles of scope that grant at least the same access as given one
+func RolesAtLeast(scope, accessLevel string) []Role {
+	min, ok := FindRole(scope, accessLevel)
+	if !ok {
+		return nil
+	}
+
+	result := make([]Role, 0)
+	for _, role := range scopeRoles[scope] {
+		if role.AtLeast(min) {
+			result = append(result, role)
+		}
+	}
+
+	return result
+}
+
+// IsRoleAtLeast compares access levels of scope, unknown ones are never at least anything
+func IsRoleAtLeast(scope, accessLevel, min string) bool {
+	role, ok := FindRole(scope, accessLevel)
+	if !ok {
+		return false
+	}
+
+	minRole, ok := FindRole(scope, min)
+	if !ok {
+		return false
+	}
+
+	return role.AtLeast(minRole)
+}
+
+// RoleName returns human-readable name of access level, custom roles are named as is
+func RoleName(accessLevel string) string {
+	if accessLevel == "" {
+		return noAccessName
+	}
+
+	for _, scope := range []string{Repository, Organization, Team} {
+		if role, ok := FindRole(scope, accessLevel); ok {
+			return role.Name
+		}
+	}
+
+	return accessLevel
+}
+
+// RoleNames returns names of all known access levels
+func RoleNames() map[string]string {
+	result := map[string]string{"": noAccessName}
+
+	for _, roles := range scopeRoles {
+		for _, role := range roles {
+			result[role.Value] = role.Name
+		}
+	}
+
+	return result
+}
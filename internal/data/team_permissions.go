@@ -0,0 +1,28 @@
+package data
+
+import "time"
+
+type TeamPermissions interface {
+	New() TeamPermissions
+
+	Upsert(permission TeamPermission) error
+	Delete() error
+	Select() ([]TeamPermission, error)
+	Get() (*TeamPermission, error)
+
+	FilterByTeamLinks(teamLinks ...string) TeamPermissions
+	FilterByRepoLinks(repoLinks ...string) TeamPermissions
+	FilterByGithubIds(githubIds ...int64) TeamPermissions
+	FilterByUserIds(userIds ...int64) TeamPermissions
+	FilterByUsernames(usernames ...string) TeamPermissions
+	FilterByLowerTime(time time.Time) TeamPermissions
+}
+
+// TeamPermission is access level team has in repository, all team members inherit it
+type TeamPermission struct {
+	TeamLink    string    `json:"team_link" db:"team_link" structs:"team_link"`
+	RepoLink    string    `json:"repo_link" db:"repo_link" structs:"repo_link"`
+	AccessLevel string    `json:"role_name" db:"access_level" structs:"access_level"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" structs:"-"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" structs:"-"`
+}
@@ -1,8 +1,8 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -11,7 +11,7 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) AddOrUpdateUserInRepositoryFromApi(link, username, permission string) (*data.Permission, error) {
+func (g *github) AddOrUpdateUserInRepositoryFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error) {
 	jsonBody, err := json.Marshal(struct {
 		Permission string `json:"permission"`
 	}{
@@ -21,17 +21,21 @@ func (g *github) AddOrUpdateUserInRepositoryFromApi(link, username, permission s
 		return nil, errors.Wrap(err, "failed to marshal body")
 	}
 
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodPut,
-		Link:   fmt.Sprintf("https://api.github.com/repos/%s/collaborators/%s", link, username),
-		Body:   jsonBody,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodPut,
+		Context:   ctx,
+		Link:      g.endpoint("/repos/%s/collaborators/%s", link, username),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -40,27 +44,55 @@ func (g *github) AddOrUpdateUserInRepositoryFromApi(link, username, permission s
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return nil, nil
-	}
 
 	//we updated permission
 	if res.StatusCode == http.StatusNoContent {
 		return &data.Permission{
-			Link:        link,
-			Username:    username,
-			AccessLevel: permission,
-			Type:        data.Repository,
+			Link:             link,
+			Username:         username,
+			AccessLevel:      permission,
+			Type:             data.Repository,
+			CollaboratorKind: data.CollaboratorDirect,
 		}, nil
 	}
 
 	return populateAddUserInRepositoryResponse(res)
 }
 
-func (g *github) AddOrUpdateUserInOrganizationFromApi(link, username, permission string) (*data.Permission, error) {
+func (g *github) AddOrUpdateUserInOrganizationFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error) {
+	if data.IsOrganizationRole(permission) {
+		return g.AssignOrganizationRoleFromApi(ctx, link, username, permission)
+	}
+
+	membership, err := g.setOrganizationMembershipFromApi(ctx, link, username, permission)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set organization membership")
+	}
+	if membership == nil || membership.Invitation != nil {
+		return membership, nil
+	}
+
+	//membership role replaces roles assigned apart from it
+	roles, err := g.GetOrganizationRolesFromApi(ctx, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get organization roles")
+	}
+
+	err = g.unassignOrganizationRolesFromApi(ctx, link, username, roles, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unassign organization roles")
+	}
+
+	return membership, nil
+}
+
+func (g *github) setOrganizationMembershipFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error) {
 	jsonBody, err := json.Marshal(struct {
 		Permission string `json:"role"`
 	}{
@@ -70,17 +102,21 @@ func (g *github) AddOrUpdateUserInOrganizationFromApi(link, username, permission
 		return nil, errors.Wrap(err, "failed to marshal body")
 	}
 
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodPut,
-		Link:   fmt.Sprintf("https://api.github.com/orgs/%s/memberships/%s", link, username),
-		Body:   jsonBody,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodPut,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/memberships/%s", link, username),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -89,12 +125,73 @@ func (g *github) AddOrUpdateUserInOrganizationFromApi(link, username, permission
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
+
+	return populateAddUserInOrganizationResponse(res)
+}
+
+func (g *github) AddOrUpdateUserInTeamFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error) {
+	jsonBody, err := json.Marshal(struct {
+		Permission string `json:"role"`
+	}{
+		Permission: permission,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal body")
+	}
+
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	//team link `org/teams/slug` matches team api path
+	params := data.RequestParams{
+		Method:    http.MethodPut,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/memberships/%s", link, username),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
 
-	return populateAddUserInOrganizationResponse(res)
+	membership, err := populateAddUserInTeamResponse(res, link, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to populate response")
+	}
+
+	//team membership response doesn't contain user
+	user, err := g.GetUserFromApi(ctx, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user from api")
+	}
+	if user == nil {
+		return nil, errors.Errorf("user `%s` wasn't found", username)
+	}
+
+	membership.GithubId = user.GithubId
+	membership.AvatarUrl = user.AvatarUrl
+
+	return membership, nil
 }
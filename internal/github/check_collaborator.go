@@ -1,8 +1,10 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
@@ -10,29 +12,36 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) CheckUserFromApi(link, username, typeTo string) (*data.Permission, error) {
+func (g *github) CheckUserFromApi(ctx context.Context, link, username, typeTo string) (*data.Permission, error) {
 	switch typeTo {
 	case data.Repository:
-		return g.CheckRepositoryCollaborator(link, username)
+		return g.CheckRepositoryCollaborator(ctx, link, username)
 	case data.Organization:
-		return g.CheckOrganizationCollaborator(link, username)
+		return g.CheckOrganizationCollaborator(ctx, link, username)
+	case data.Team:
+		return g.CheckTeamMember(ctx, link, username)
 	default:
 		return nil, errors.Errorf("failed to check `%s` with `%s` type", link, typeTo)
 	}
 }
 
-func (g *github) CheckRepositoryCollaborator(link, username string) (*data.Permission, error) {
+func (g *github) CheckRepositoryCollaborator(ctx context.Context, link, username string) (*data.Permission, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/repos/%s/collaborators/%s/permission", link, username),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/repos/%s/collaborators/%s/permission", link, username),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -41,28 +50,74 @@ func (g *github) CheckRepositoryCollaborator(link, username string) (*data.Permi
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return nil, nil
-	}
 
 	return populateCheckRepositoryCollaboratorResponse(res, link, username)
 }
 
-func (g *github) CheckOrganizationCollaborator(link, username string) (*data.Permission, error) {
+func (g *github) CheckOrganizationCollaborator(ctx context.Context, link, username string) (*data.Permission, error) {
+	membership, err := g.checkOrganizationMembershipFromApi(ctx, link, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check organization membership")
+	}
+	//membership tells about billing manager, other roles are assigned to members only
+	if membership == nil || membership.AccessLevel == organizationAdminRole || membership.AccessLevel == data.BillingManager {
+		return membership, nil
+	}
+
+	//membership doesn't tell about roles assigned apart from it
+	roles, err := g.GetOrganizationRolesFromApi(ctx, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get organization roles")
+	}
+
+	for _, role := range assignedOrganizationRoles {
+		roleId, ok := roles[role]
+		if !ok {
+			continue
+		}
+
+		users, err := g.GetOrganizationRoleUsersFromApi(ctx, link, roleId)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to get `%s` role users", role))
+		}
+
+		for _, user := range users {
+			if !strings.EqualFold(user.Username, username) {
+				continue
+			}
+
+			membership.AccessLevel = role
+
+			return membership, nil
+		}
+	}
+
+	return membership, nil
+}
+
+func (g *github) checkOrganizationMembershipFromApi(ctx context.Context, link, username string) (*data.Permission, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/orgs/%s/memberships/%s", link, username),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/memberships/%s", link, username),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -71,12 +126,48 @@ func (g *github) CheckOrganizationCollaborator(link, username string) (*data.Per
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
+
+	return populateCheckOrganizationCollaboratorResponse(res, link, username)
+}
+
+func (g *github) CheckTeamMember(ctx context.Context, link, username string) (*data.Permission, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	//team link `org/teams/slug` matches team api path
+	params := data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/memberships/%s", link, username),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
 
-	return populateCheckOrganizationCollaboratorResponse(res, link, username)
+	return populateAddUserInTeamResponse(res, link, username)
 }
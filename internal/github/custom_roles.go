@@ -0,0 +1,59 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (g *github) GetCustomRepositoryRolesFromApi(ctx context.Context, link string) ([]data.CustomRole, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/custom-repository-roles", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//organization plan doesn't support custom roles
+	if helpers.IsNotFound(err) {
+		return make([]data.CustomRole, 0), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
+
+	var response struct {
+		CustomRoles []data.CustomRole `json:"custom_roles"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	for i := range response.CustomRoles {
+		response.CustomRoles[i].Link = link
+	}
+
+	return response.CustomRoles, nil
+}
@@ -1,8 +1,8 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -11,18 +11,23 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) FindRepositoryOwner(link string) (string, error) {
+func (g *github) FindRepositoryOwner(ctx context.Context, link string) (string, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/repos/%s", link),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/repos/%s", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -34,9 +39,6 @@ func (g *github) FindRepositoryOwner(link string) (string, error) {
 	if err != nil {
 		return "", errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return "", errors.Errorf("error in response, status %v", res.StatusCode)
-	}
 
 	response := struct {
 		Owner struct {
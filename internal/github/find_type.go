@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,8 +12,20 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) FindType(link string) (*TypeSub, error) {
-	repo, err := g.GetRepositoryFromApi(link)
+func (g *github) FindType(ctx context.Context, link string) (*TypeSub, error) {
+	if isTeamLink(link) {
+		team, err := g.GetTeamFromApi(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+		if team != nil {
+			return &TypeSub{data.Team, *team}, nil
+		}
+
+		return nil, nil
+	}
+
+	repo, err := g.GetRepositoryFromApi(ctx, link)
 	if err != nil {
 		return nil, err
 	}
@@ -20,7 +33,7 @@ func (g *github) FindType(link string) (*TypeSub, error) {
 		return &TypeSub{data.Repository, *repo}, err
 	}
 
-	org, err := g.GetOrganizationFromApi(link)
+	org, err := g.GetOrganizationFromApi(ctx, link)
 	if err != nil {
 		return nil, err
 	}
@@ -31,18 +44,46 @@ func (g *github) FindType(link string) (*TypeSub, error) {
 	return nil, nil
 }
 
-func (g *github) GetRepositoryFromApi(link string) (*data.Sub, error) {
+// targetExists tells if link of type exists, removal that is answered with not found for existing target
+// has nothing to remove, so repeated job succeeds
+func (g *github) targetExists(ctx context.Context, link, typeTo string) (bool, error) {
+	var target *data.Sub
+	var err error
+
+	switch typeTo {
+	case data.Repository:
+		target, err = g.GetRepositoryFromApi(ctx, link)
+	case data.Organization:
+		target, err = g.GetOrganizationFromApi(ctx, link)
+	case data.Team:
+		target, err = g.GetTeamFromApi(ctx, link)
+	default:
+		return false, errors.Errorf("unexpected type `%s`", typeTo)
+	}
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("failed to get `%s`", link))
+	}
+
+	return target != nil, nil
+}
+
+func (g *github) GetRepositoryFromApi(ctx context.Context, link string) (*data.Sub, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/repos/%s", link),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/repos/%s", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -51,12 +92,12 @@ func (g *github) GetRepositoryFromApi(link string) (*data.Sub, error) {
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return nil, nil
-	}
 
 	var response data.Sub
 	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
@@ -67,18 +108,23 @@ func (g *github) GetRepositoryFromApi(link string) (*data.Sub, error) {
 	return &response, nil
 }
 
-func (g *github) GetOrganizationFromApi(link string) (*data.Sub, error) {
+func (g *github) GetOrganizationFromApi(ctx context.Context, link string) (*data.Sub, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/orgs/%s", link),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -87,12 +133,48 @@ func (g *github) GetOrganizationFromApi(link string) (*data.Sub, error) {
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
+
+	return populateGetOrganizationResponse(res)
+}
+
+func (g *github) GetTeamFromApi(ctx context.Context, link string) (*data.Sub, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	//team link `org/teams/slug` matches team api path
+	params := data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
 
-	return populateGetOrganizationResponse(res)
+	return populateGetTeamResponse(res, LinkOwner(link))
 }
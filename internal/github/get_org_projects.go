@@ -1,8 +1,7 @@
 package github
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
 	"time"
 
@@ -11,29 +10,54 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) GetProjectsFromApi(link string) ([]data.Sub, error) {
-	response, err := helpers.MakeRequestWithPagination(data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/orgs/%s/repos", link),
-		Body:   nil,
-		Query: map[string]string{
-			"per_page": "100",
-		},
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
-	})
+func (g *github) GetProjectsFromApi(ctx context.Context, link string) ([]data.Sub, error) {
+	result := make([]data.Sub, 0)
+
+	for pageLink := ""; ; {
+		page, err := g.GetProjectsPageFromApi(ctx, link, pageLink)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get projects page")
+		}
+
+		result = append(result, page.Items...)
+		if page.Next == "" {
+			return result, nil
+		}
+		pageLink = page.Next
+	}
+}
+
+// GetProjectsPageFromApi returns one page of organization repositories, empty page link stands for the first one,
+// so every page can be requested with its own pqueue call
+func (g *github) GetProjectsPageFromApi(ctx context.Context, link, pageLink string) (data.Page[data.Sub], error) {
+	header, err := g.header(readCredential, link)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to make request with pagination")
+		return data.Page[data.Sub]{}, errors.Wrap(err, "failed to build request header")
 	}
 
-	var result []data.Sub
-	if err = json.Unmarshal(response, &result); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal body")
+	params := data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      pageLink,
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+	if pageLink == "" {
+		params.Link = g.endpoint("/orgs/%s/repos", link)
+		params.Query = map[string]string{
+			"per_page": "100",
+		}
+	}
+
+	page, err := helpers.GetPage[data.Sub](params)
+	if err != nil {
+		return data.Page[data.Sub]{}, errors.Wrap(err, "failed to get page")
 	}
 
-	return result, nil
+	return page, nil
 }
@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (g *github) GetTeamsFromApi(ctx context.Context, link string) ([]data.Sub, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	teams, err := helpers.CollectPages[teamResponse](data.RequestParams{
+		Method:  http.MethodGet,
+		Context: ctx,
+		Link:    g.endpoint("/orgs/%s/teams", link),
+		Body:    nil,
+		Query: map[string]string{
+			"per_page": "100",
+		},
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request with pagination")
+	}
+
+	result := make([]data.Sub, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, *team.toSub(link))
+	}
+
+	return result, nil
+}
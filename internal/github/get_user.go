@@ -1,8 +1,8 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -11,18 +11,23 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) GetUserFromApi(username string) (*data.User, error) {
+func (g *github) GetUserFromApi(ctx context.Context, username string) (*data.User, error) {
+	header, err := g.header(readCredential, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   fmt.Sprintf("https://api.github.com/users/%s", username),
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/users/%s", username),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, ""),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -31,12 +36,12 @@ func (g *github) GetUserFromApi(username string) (*data.User, error) {
 	}
 
 	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return nil, nil
-	}
 
 	var response data.Permission
 	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
@@ -1,7 +1,7 @@
 package github
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,33 +11,120 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) GetUsersFromApi(link, typeTo string) ([]data.Permission, error) {
-	resultLink := fmt.Sprintf("https://api.github.com/repos/%s/collaborators", link)
-	if typeTo == data.Organization {
-		resultLink = fmt.Sprintf("https://api.github.com/orgs/%s/members", link)
-	}
-
-	response, err := helpers.MakeRequestWithPagination(data.RequestParams{
-		Method: http.MethodGet,
-		Link:   resultLink,
-		Body:   nil,
-		Query: map[string]string{
-			"per_page": "100",
-		},
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+// GetUsersFromApi prefers bulk GraphQL queries, REST API is used when GraphQL one is unavailable or fails
+func (g *github) GetUsersFromApi(ctx context.Context, link, typeTo string) ([]data.Permission, error) {
+	result, err := g.getUsersFromGraphql(ctx, link, typeTo)
+	if err == nil {
+		return result, nil
+	}
+	if err != errGraphqlUnavailable {
+		g.log.WithError(err).Warnf("failed to get users of `%s` with graphql, using rest api", link)
+	}
+
+	switch typeTo {
+	case data.Team:
+		return g.getTeamMembersFromApi(ctx, link)
+	case data.Organization:
+		return g.getOrganizationMembersFromApi(ctx, link)
+	default:
+		return g.getRepositoryCollaboratorsFromApi(ctx, link)
+	}
+}
+
+// getRepositoryCollaboratorsFromApi tells collaborator kinds apart with affiliation filters,
+// everyone who is neither outside nor direct collaborator has access as organization member
+func (g *github) getRepositoryCollaboratorsFromApi(ctx context.Context, link string) ([]data.Permission, error) {
+	endpoint := g.endpoint("/repos/%s/collaborators", link)
+
+	result, err := g.getUsersFromApi(ctx, link, endpoint, nil, data.CollaboratorMember)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get all collaborators")
+	}
+
+	kinds := make(map[int64]string)
+	for _, kind := range []string{data.CollaboratorDirect, data.CollaboratorOutside} {
+		collaborators, err := g.getUsersFromApi(ctx, link, endpoint, map[string]string{"affiliation": kind}, kind)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to get %s collaborators", kind))
+		}
+
+		//outside collaborators are direct ones too, so they must override
+		for _, collaborator := range collaborators {
+			kinds[collaborator.GithubId] = kind
+		}
+	}
+
+	for i := range result {
+		kind, ok := kinds[result[i].GithubId]
+		if ok {
+			result[i].CollaboratorKind = kind
+		}
+	}
+
+	return result, nil
+}
+
+func (g *github) getUsersFromApi(ctx context.Context, link, endpoint string, query map[string]string, kind string) ([]data.Permission, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := map[string]string{
+		"per_page": "100",
+	}
+	for key, value := range query {
+		params[key] = value
+	}
+
+	result, err := helpers.CollectPages[data.Permission](data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      endpoint,
+		Body:      nil,
+		Query:     params,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to make request with pagination")
 	}
 
-	var result []data.Permission
-	if err = json.Unmarshal(response, &result); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal body")
+	for i := range result {
+		result[i].CollaboratorKind = kind
+	}
+
+	return result, nil
+}
+
+// getTeamMembersFromApi lists members by role, because members list doesn't contain roles itself
+func (g *github) getTeamMembersFromApi(ctx context.Context, link string) ([]data.Permission, error) {
+	result := make([]data.Permission, 0)
+
+	for _, role := range []string{teamMaintainerRole, teamMemberRole} {
+		members, err := g.getTeamMembersByRoleFromApi(ctx, link, role)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get team members by role")
+		}
+
+		result = append(result, members...)
+	}
+
+	return result, nil
+}
+
+func (g *github) getTeamMembersByRoleFromApi(ctx context.Context, link, role string) ([]data.Permission, error) {
+	//team link `org/teams/slug` matches team api path
+	result, err := g.getUsersFromApi(ctx, link, g.endpoint("/orgs/%s/members", link), map[string]string{"role": role}, data.CollaboratorMember)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team members")
+	}
+
+	for i := range result {
+		result[i].AccessLevel = role
 	}
 
 	return result, nil
@@ -2,36 +2,67 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
 	"gitlab.com/distributed_lab/logan/v3"
 
 	"github.com/acs-dl/github-module-svc/internal/config"
 	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/data/postgres"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
 type GithubClient interface {
-	AddUserFromApi(typeTo, link, username, permission string) (*data.Permission, error)
-	UpdateUserFromApi(typeTo, link, username, permission string) (*data.Permission, error)
-	AddOrUpdateUserInRepositoryFromApi(link, username, permission string) (*data.Permission, error)
-	AddOrUpdateUserInOrganizationFromApi(link, username, permission string) (*data.Permission, error)
+	AddUserFromApi(ctx context.Context, typeTo, link, username, permission string) (*data.Permission, error)
+	UpdateUserFromApi(ctx context.Context, typeTo, link, username, permission string) (*data.Permission, error)
+	AddOrUpdateUserInRepositoryFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error)
+	AddOrUpdateUserInOrganizationFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error)
+	AddOrUpdateUserInTeamFromApi(ctx context.Context, link, username, permission string) (*data.Permission, error)
+
+	GetUsersFromApi(ctx context.Context, link, typeTo string) ([]data.Permission, error)
+	GetUserFromApi(ctx context.Context, username string) (*data.User, error)
+
+	RemoveUserFromApi(ctx context.Context, link, username, typeTo string) error
+
+	GetOrganizationFromApi(ctx context.Context, link string) (*data.Sub, error)
+	GetRepositoryFromApi(ctx context.Context, link string) (*data.Sub, error)
+	GetTeamFromApi(ctx context.Context, link string) (*data.Sub, error)
+
+	CheckUserFromApi(ctx context.Context, link, username, typeTo string) (*data.Permission, error)
+	CheckRepositoryCollaborator(ctx context.Context, link, username string) (*data.Permission, error)
+	CheckOrganizationCollaborator(ctx context.Context, link, username string) (*data.Permission, error)
+	CheckTeamMember(ctx context.Context, link, username string) (*data.Permission, error)
 
-	GetUsersFromApi(link, typeTo string) ([]data.Permission, error)
-	GetUserFromApi(username string) (*data.User, error)
+	FindType(ctx context.Context, link string) (*TypeSub, error)
+	FindRepositoryOwner(ctx context.Context, link string) (string, error)
 
-	RemoveUserFromApi(link, username, typeTo string) error
+	SearchByFromApi(ctx context.Context, username string) ([]data.User, error)
+	GetProjectsFromApi(ctx context.Context, link string) ([]data.Sub, error)
+	GetProjectsPageFromApi(ctx context.Context, link, pageLink string) (data.Page[data.Sub], error)
+	GetTeamsFromApi(ctx context.Context, link string) ([]data.Sub, error)
 
-	GetOrganizationFromApi(link string) (*data.Sub, error)
-	GetRepositoryFromApi(link string) (*data.Sub, error)
+	GetTeamRepositoriesFromApi(ctx context.Context, teamLink string) ([]data.TeamPermission, error)
+	AddOrUpdateTeamInRepositoryFromApi(ctx context.Context, teamLink, repoLink, permission string) (*data.TeamPermission, error)
+	RemoveTeamFromRepositoryFromApi(ctx context.Context, teamLink, repoLink string) error
 
-	CheckUserFromApi(link, username, typeTo string) (*data.Permission, error)
-	CheckRepositoryCollaborator(link, username string) (*data.Permission, error)
-	CheckOrganizationCollaborator(link, username string) (*data.Permission, error)
+	GetInvitationsFromApi(ctx context.Context, link, typeTo string) ([]data.Invitation, error)
+	CancelInvitationFromApi(ctx context.Context, link, username, typeTo string, invitationId int64) error
+	InviteToOrganizationByEmailFromApi(ctx context.Context, link, email, role string) (*data.Invitation, error)
 
-	FindType(link string) (*TypeSub, error)
-	FindRepositoryOwner(link string) (string, error)
+	GetOutsideCollaboratorsFromApi(ctx context.Context, link string) ([]data.Permission, error)
+	ConvertMemberToOutsideCollaboratorFromApi(ctx context.Context, link, username string) error
 
-	SearchByFromApi(username string) ([]data.User, error)
-	GetProjectsFromApi(link string) ([]data.Sub, error)
+	GetCustomRepositoryRolesFromApi(ctx context.Context, link string) ([]data.CustomRole, error)
+	AssignOrganizationRoleFromApi(ctx context.Context, link, username, role string) (*data.Permission, error)
+	RevokeOrganizationRoleFromApi(ctx context.Context, link, username, role string) error
+	GetOrganizationRolesFromApi(ctx context.Context, link string) (map[string]int64, error)
+	GetOrganizationRoleUsersFromApi(ctx context.Context, link string, roleId int64) ([]data.Permission, error)
+
+	ObserveRateLimitsFromApi(ctx context.Context) error
 }
 
 type TypeSub struct {
@@ -39,18 +70,141 @@ type TypeSub struct {
 	Sub  data.Sub
 }
 
+const (
+	teamsPathSegment = "teams"
+
+	teamMemberRole     = data.RoleMember
+	teamMaintainerRole = data.RoleMaintainer
+
+	membershipPendingState = "pending"
+
+	organizationAdminRole        = data.RoleAdmin
+	organizationMemberRole       = data.RoleMember
+	organizationDirectMemberRole = "direct_member"
+)
+
+type credential int
+
+const (
+	// readCredential is low-privilege token for lookups, it has its own rate limit budget
+	readCredential credential = iota
+	// writeCredential is admin token used only for mutations
+	writeCredential
+)
+
 type github struct {
-	superUserToken string
-	userToken      string
-	log            *logan.Entry
+	tokens     map[credential]tokenSource
+	apiUrl     string
+	apiVersion string
+	graphqlUrl string
+	httpClient *http.Client
+	// cache keeps validators of GET responses to make conditional requests
+	cache data.HttpCache
+	// pqueues pace themselves by budget reported in responses made with their credentials
+	pqueues *pqueue.PQueues
+	owners  []string
+	log     *logan.Entry
+}
+
+func NewGithubAsInterface(cfg config.Config, ctx context.Context) interface{} {
+	client := &github{
+		tokens: map[credential]tokenSource{
+			readCredential:  staticTokenSource(cfg.Github().UsualToken),
+			writeCredential: staticTokenSource(cfg.Github().SuperToken),
+		},
+		apiUrl:     cfg.Github().ApiUrl,
+		apiVersion: cfg.Github().ApiVersion,
+		graphqlUrl: cfg.Github().GraphqlUrl,
+		httpClient: cfg.Github().HttpClient,
+		cache:      postgres.NewHttpCacheQ(cfg.DB()),
+		pqueues:    pqueue.PQueuesInstance(ctx),
+		log:        cfg.Log(),
+	}
+
+	//installation token permissions are managed by app itself, so both credentials share it
+	if cfg.Github().App != nil {
+		appTokens := newAppTokenSource(*cfg.Github().App, client)
+		client.tokens[readCredential] = appTokens
+		client.tokens[writeCredential] = appTokens
+	}
+
+	readOwners := make(map[string]tokenSource)
+	writeOwners := make(map[string]tokenSource)
+	for owner, ownerCfg := range cfg.Github().Owners {
+		switch {
+		case ownerCfg.App != nil:
+			ownerTokens := newAppTokenSource(*ownerCfg.App, client)
+			readOwners[owner] = ownerTokens
+			writeOwners[owner] = ownerTokens
+		case ownerCfg.UsesApp():
+			//owner without own credentials uses its installation of module app
+			readOwners[owner] = client.tokens[readCredential]
+			writeOwners[owner] = client.tokens[writeCredential]
+		default:
+			readOwners[owner] = staticTokenSource(ownerCfg.UsualToken)
+			writeOwners[owner] = staticTokenSource(ownerCfg.SuperToken)
+		}
+		client.owners = append(client.owners, owner)
+	}
+
+	client.tokens[readCredential] = ownerTokenSource{defaultTokens: client.tokens[readCredential], owners: readOwners}
+	client.tokens[writeCredential] = ownerTokenSource{defaultTokens: client.tokens[writeCredential], owners: writeOwners}
+
+	return interface{}(client)
+}
+
+// endpoint builds full api link from path relative to configured api root
+func (g *github) endpoint(path string, args ...any) string {
+	return g.apiUrl + fmt.Sprintf(path, args...)
+}
+
+// header authorizes request with given credential of the account that owns link
+func (g *github) header(cred credential, link string) (map[string]string, error) {
+	token, err := g.tokens[cred].Token(LinkOwner(link))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get token")
+	}
+
+	return g.tokenHeader(token), nil
+}
+
+// rateLimit returns queue that spends budget of given credential of the account that owns link
+func (g *github) rateLimit(cred credential, link string) data.RateLimitObserver {
+	queues := g.pqueues.ForLink(link)
+	if cred == writeCredential {
+		return queues.SuperUserPQueue
+	}
+
+	return queues.UserPQueue
+}
+
+func (g *github) tokenHeader(token string) map[string]string {
+	header := map[string]string{
+		"Accept":        data.AcceptHeader,
+		"Authorization": "Bearer " + token,
+	}
+
+	//some Enterprise Server versions reject requests with unknown api version
+	if g.apiVersion != "" {
+		header["X-GitHub-Api-Version"] = g.apiVersion
+	}
+
+	return header
+}
+
+// LinkOwner returns organization or user login from `owner/repo` like link
+func LinkOwner(link string) string {
+	return strings.Split(link, "/")[0]
+}
+
+// isTeamLink checks if link has `org/teams/slug` form
+func isTeamLink(link string) bool {
+	parts := strings.Split(link, "/")
+	return len(parts) == 3 && parts[1] == teamsPathSegment
 }
 
-func NewGithubAsInterface(cfg config.Config, _ context.Context) interface{} {
-	return interface{}(&github{
-		superUserToken: cfg.Github().SuperToken,
-		userToken:      cfg.Github().UsualToken,
-		log:            cfg.Log(),
-	})
+func teamLink(org, slug string) string {
+	return org + "/" + teamsPathSegment + "/" + slug
 }
 
 func GithubClientInstance(ctx context.Context) GithubClient {
@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// graphqlPageSize is the largest page GitHub allows, query with such connection costs one point
+const graphqlPageSize = 100
+
+// errGraphqlUnavailable means that REST API must be used instead
+var errGraphqlUnavailable = errors.New("graphql api is unavailable")
+
+type graphqlRateLimit struct {
+	Cost      int64     `json:"cost"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+type graphqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphqlUser struct {
+	Login      string `json:"login"`
+	DatabaseId int64  `json:"databaseId"`
+	AvatarUrl  string `json:"avatarUrl"`
+}
+
+// graphqlRateLimitField is added to every query to know its real cost and remaining points
+const graphqlRateLimitField = `rateLimit { cost remaining resetAt }`
+
+// graphql makes query on behalf of link owner and decodes `data` field into result,
+// query must select `rateLimit` with graphqlRateLimitField
+func (g *github) graphql(ctx context.Context, link, query string, variables map[string]any, result any) error {
+	if g.graphqlUrl == "" {
+		return errGraphqlUnavailable
+	}
+
+	jsonBody, err := json.Marshal(struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal body")
+	}
+
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	//graphql budget is reported in headers like REST one, so queue paces its graphql bucket by them
+	params := data.RequestParams{
+		Method:    http.MethodPost,
+		Context:   ctx,
+		Link:      g.graphqlUrl,
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//instance without GraphQL API
+	if helpers.IsNotFound(err) {
+		return errGraphqlUnavailable
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	if len(response.Errors) != 0 {
+		return errors.Errorf("graphql error `%s`: %s", response.Errors[0].Type, response.Errors[0].Message)
+	}
+
+	var rateLimit struct {
+		RateLimit *graphqlRateLimit `json:"rateLimit"`
+	}
+	if err = json.Unmarshal(response.Data, &rateLimit); err != nil {
+		return errors.Wrap(err, "failed to unmarshal rate limit")
+	}
+
+	if rateLimit.RateLimit != nil {
+		g.log.Debugf("graphql query cost %d points, %d remaining for `%s`", rateLimit.RateLimit.Cost, rateLimit.RateLimit.Remaining, LinkOwner(link))
+	}
+
+	if err = json.Unmarshal(response.Data, result); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to unmarshal data of `%s`", link))
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const graphqlOrganizationMembersQuery = `query($login: String!, $first: Int!, $cursor: String) {
+  organization(login: $login) {
+    membersWithRole(first: $first, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      edges { role node { login databaseId avatarUrl } }
+    }
+  }
+  ` + graphqlRateLimitField + `
+}`
+
+const graphqlRepositoryCollaboratorsQuery = `query($owner: String!, $name: String!, $first: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    owner { __typename }
+    collaborators(first: $first, after: $cursor, affiliation: ALL) {
+      pageInfo { hasNextPage endCursor }
+      edges {
+        permission
+        permissionSources { source { __typename } }
+        node { login databaseId avatarUrl }
+      }
+    }
+  }
+  ` + graphqlRateLimitField + `
+}`
+
+const graphqlTeamMembersQuery = `query($login: String!, $slug: String!, $first: Int!, $cursor: String) {
+  organization(login: $login) {
+    team(slug: $slug) {
+      members(first: $first, after: $cursor, membership: ALL) {
+        pageInfo { hasNextPage endCursor }
+        edges { role node { login databaseId avatarUrl } }
+      }
+    }
+  }
+  ` + graphqlRateLimitField + `
+}`
+
+const (
+	graphqlOrganizationType = "Organization"
+	graphqlRepositoryType   = "Repository"
+)
+
+func (g *github) getUsersFromGraphql(ctx context.Context, link, typeTo string) ([]data.Permission, error) {
+	switch typeTo {
+	case data.Organization:
+		//GraphQL API doesn't expose roles assigned apart from membership, they are listed with REST API separately
+		return g.getOrganizationMembersFromGraphql(ctx, link)
+	case data.Repository:
+		return g.getRepositoryCollaboratorsFromGraphql(ctx, link)
+	case data.Team:
+		return g.getTeamMembersFromGraphql(ctx, link)
+	default:
+		return nil, errGraphqlUnavailable
+	}
+}
+
+func (g *github) getOrganizationMembersFromGraphql(ctx context.Context, link string) ([]data.Permission, error) {
+	result := make([]data.Permission, 0)
+
+	for cursor := (*string)(nil); ; {
+		var response struct {
+			Organization *struct {
+				MembersWithRole struct {
+					PageInfo graphqlPageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Role string      `json:"role"`
+						Node graphqlUser `json:"node"`
+					} `json:"edges"`
+				} `json:"membersWithRole"`
+			} `json:"organization"`
+		}
+
+		err := g.graphql(ctx, link, graphqlOrganizationMembersQuery, map[string]any{
+			"login":  link,
+			"first":  graphqlPageSize,
+			"cursor": cursor,
+		}, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Organization == nil {
+			return nil, errors.Errorf("organization `%s` wasn't found", link)
+		}
+
+		for _, edge := range response.Organization.MembersWithRole.Edges {
+			result = append(result, newGraphqlPermission(edge.Node, strings.ToLower(edge.Role), data.CollaboratorMember))
+		}
+
+		pageInfo := response.Organization.MembersWithRole.PageInfo
+		if !pageInfo.HasNextPage {
+			return result, nil
+		}
+		cursor = &pageInfo.EndCursor
+	}
+}
+
+// getRepositoryCollaboratorsFromGraphql tells collaborator kinds apart by permission sources:
+// own repository source means direct access, which is outside one for organization non-members
+func (g *github) getRepositoryCollaboratorsFromGraphql(ctx context.Context, link string) ([]data.Permission, error) {
+	parts := strings.Split(link, "/")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("unexpected repository link `%s`", link)
+	}
+
+	result := make([]data.Permission, 0)
+
+	for cursor := (*string)(nil); ; {
+		var response struct {
+			Repository *struct {
+				Owner struct {
+					Typename string `json:"__typename"`
+				} `json:"owner"`
+				Collaborators struct {
+					PageInfo graphqlPageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Permission        string `json:"permission"`
+						PermissionSources []struct {
+							Source struct {
+								Typename string `json:"__typename"`
+							} `json:"source"`
+						} `json:"permissionSources"`
+						Node graphqlUser `json:"node"`
+					} `json:"edges"`
+				} `json:"collaborators"`
+			} `json:"repository"`
+		}
+
+		err := g.graphql(ctx, link, graphqlRepositoryCollaboratorsQuery, map[string]any{
+			"owner":  parts[0],
+			"name":   parts[1],
+			"first":  graphqlPageSize,
+			"cursor": cursor,
+		}, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Repository == nil {
+			return nil, errors.Errorf("repository `%s` wasn't found", link)
+		}
+
+		organizationOwned := response.Repository.Owner.Typename == graphqlOrganizationType
+
+		for _, edge := range response.Repository.Collaborators.Edges {
+			direct, member := false, false
+			for _, permissionSource := range edge.PermissionSources {
+				switch permissionSource.Source.Typename {
+				case graphqlRepositoryType:
+					direct = true
+				case graphqlOrganizationType:
+					member = true
+				}
+			}
+
+			kind := data.CollaboratorMember
+			if direct {
+				kind = data.CollaboratorDirect
+				if organizationOwned && !member {
+					kind = data.CollaboratorOutside
+				}
+			}
+
+			result = append(result, newGraphqlPermission(edge.Node, strings.ToLower(edge.Permission), kind))
+		}
+
+		pageInfo := response.Repository.Collaborators.PageInfo
+		if !pageInfo.HasNextPage {
+			return result, nil
+		}
+		cursor = &pageInfo.EndCursor
+	}
+}
+
+func (g *github) getTeamMembersFromGraphql(ctx context.Context, link string) ([]data.Permission, error) {
+	parts := strings.Split(link, "/")
+	if !isTeamLink(link) {
+		return nil, errors.Errorf("unexpected team link `%s`", link)
+	}
+
+	result := make([]data.Permission, 0)
+
+	for cursor := (*string)(nil); ; {
+		var response struct {
+			Organization *struct {
+				Team *struct {
+					Members struct {
+						PageInfo graphqlPageInfo `json:"pageInfo"`
+						Edges    []struct {
+							Role string      `json:"role"`
+							Node graphqlUser `json:"node"`
+						} `json:"edges"`
+					} `json:"members"`
+				} `json:"team"`
+			} `json:"organization"`
+		}
+
+		err := g.graphql(ctx, link, graphqlTeamMembersQuery, map[string]any{
+			"login":  parts[0],
+			"slug":   parts[2],
+			"first":  graphqlPageSize,
+			"cursor": cursor,
+		}, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Organization == nil || response.Organization.Team == nil {
+			return nil, errors.Errorf("team `%s` wasn't found", link)
+		}
+
+		for _, edge := range response.Organization.Team.Members.Edges {
+			result = append(result, newGraphqlPermission(edge.Node, strings.ToLower(edge.Role), data.CollaboratorMember))
+		}
+
+		pageInfo := response.Organization.Team.Members.PageInfo
+		if !pageInfo.HasNextPage {
+			return result, nil
+		}
+		cursor = &pageInfo.EndCursor
+	}
+}
+
+func newGraphqlPermission(user graphqlUser, accessLevel, kind string) data.Permission {
+	return data.Permission{
+		Username:         user.Login,
+		GithubId:         user.DatabaseId,
+		AvatarUrl:        user.AvatarUrl,
+		AccessLevel:      data.NormalizeRole(accessLevel),
+		CollaboratorKind: kind,
+	}
+}
@@ -0,0 +1,318 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// GetInvitationsFromApi returns invitations that weren't accepted yet, expired ones included
+func (g *github) GetInvitationsFromApi(ctx context.Context, link, typeTo string) ([]data.Invitation, error) {
+	switch typeTo {
+	case data.Repository:
+		return g.getRepositoryInvitationsFromApi(ctx, link)
+	case data.Organization, data.Team:
+		return g.getMembershipInvitationsFromApi(ctx, link)
+	default:
+		return nil, errors.New("unexpected type")
+	}
+}
+
+func (g *github) getRepositoryInvitationsFromApi(ctx context.Context, link string) ([]data.Invitation, error) {
+	invitations, err := listInvitations[repositoryInvitationResponse](ctx, g, link, g.endpoint("/repos/%s/invitations", link))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list repository invitations")
+	}
+
+	result := make([]data.Invitation, len(invitations))
+	for i, invitation := range invitations {
+		state := data.InvitationPending
+		if invitation.Expired {
+			state = data.InvitationExpired
+		}
+
+		result[i] = data.Invitation{
+			InvitationId: &invitations[i].Id,
+			Username:     invitation.Invitee.Login,
+			GithubId:     &invitations[i].Invitee.Id,
+			Link:         link,
+			Type:         data.Repository,
+			AccessLevel:  data.NormalizeRole(invitation.Permissions),
+			State:        state,
+		}
+	}
+
+	return result, nil
+}
+
+// getMembershipInvitationsFromApi lists pending invitations of organization or team
+// and expired ones, which GitHub reports only as failed organization invitations
+func (g *github) getMembershipInvitationsFromApi(ctx context.Context, link string) ([]data.Invitation, error) {
+	//team link `org/teams/slug` matches team api path
+	pending, err := listInvitations[organizationInvitationResponse](ctx, g, link, g.endpoint("/orgs/%s/invitations", link))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pending invitations")
+	}
+
+	failed, err := listInvitations[organizationInvitationResponse](ctx, g, link, g.endpoint("/orgs/%s/failed_invitations", LinkOwner(link)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list failed invitations")
+	}
+
+	result := make([]data.Invitation, 0)
+	for state, invitations := range map[string][]organizationInvitationResponse{
+		data.InvitationPending: pending,
+		data.InvitationExpired: failed,
+	} {
+		for i, invitation := range invitations {
+			result = append(result, data.Invitation{
+				InvitationId: &invitations[i].Id,
+				Username:     stringValue(invitation.Login),
+				Email:        invitation.Email,
+				Link:         link,
+				State:        state,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+type repositoryInvitationResponse struct {
+	Id      int64 `json:"id"`
+	Invitee struct {
+		Login string `json:"login"`
+		Id    int64  `json:"id"`
+	} `json:"invitee"`
+	Permissions string `json:"permissions"`
+	Expired     bool   `json:"expired"`
+}
+
+type organizationInvitationResponse struct {
+	Id    int64   `json:"id"`
+	Login *string `json:"login"`
+	Email *string `json:"email"`
+}
+
+func listInvitations[T any](ctx context.Context, g *github, link, endpoint string) ([]T, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	response, err := helpers.CollectPages[T](data.RequestParams{
+		Method:  http.MethodGet,
+		Context: ctx,
+		Link:    endpoint,
+		Body:    nil,
+		Query: map[string]string{
+			"per_page": "100",
+		},
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request with pagination")
+	}
+
+	return response, nil
+}
+
+// CancelInvitationFromApi deletes pending invitation, invitation without known id is looked up
+// among pending ones, membership is never removed, so accepted invitation can't be cancelled
+func (g *github) CancelInvitationFromApi(ctx context.Context, link, username, typeTo string, invitationId int64) error {
+	if invitationId == 0 {
+		invitation, err := g.findPendingInvitation(ctx, link, username, typeTo)
+		if err != nil {
+			return errors.Wrap(err, "failed to find pending invitation")
+		}
+		if invitation == nil {
+			return errors.Errorf("no pending invitation of `%s` in `%s`", username, link)
+		}
+
+		invitationId = *invitation.InvitationId
+	}
+
+	var endpoint string
+	switch typeTo {
+	case data.Repository:
+		endpoint = g.endpoint("/repos/%s/invitations/%d", link, invitationId)
+	case data.Organization, data.Team:
+		//team invitations are invitations to organization
+		endpoint = g.endpoint("/orgs/%s/invitations/%d", LinkOwner(link), invitationId)
+	default:
+		return errors.New("unexpected type")
+	}
+
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodDelete,
+		Context:   ctx,
+		Link:      endpoint,
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	_, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//invitation is cancelled or accepted already when its target exists, so repeated job succeeds
+	if helpers.IsNotFound(err) {
+		exists, checkErr := g.invitationTargetExists(ctx, link, typeTo)
+		if checkErr != nil {
+			return errors.Wrap(checkErr, "failed to check invitation target")
+		}
+		if exists {
+			return nil
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	return nil
+}
+
+// invitationTargetExists checks repository or organization, team invitations are invitations to organization
+func (g *github) invitationTargetExists(ctx context.Context, link, typeTo string) (bool, error) {
+	if typeTo == data.Repository {
+		return g.targetExists(ctx, link, data.Repository)
+	}
+
+	return g.targetExists(ctx, LinkOwner(link), data.Organization)
+}
+
+func (g *github) findPendingInvitation(ctx context.Context, link, username, typeTo string) (*data.Invitation, error) {
+	if username == "" {
+		return nil, nil
+	}
+
+	invitations, err := g.GetInvitationsFromApi(ctx, link, typeTo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get invitations")
+	}
+
+	for i, invitation := range invitations {
+		if invitation.State == data.InvitationPending && invitation.InvitationId != nil &&
+			strings.EqualFold(invitation.Username, username) {
+			return &invitations[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (g *github) InviteToOrganizationByEmailFromApi(ctx context.Context, link, email, role string) (*data.Invitation, error) {
+	//invitations api names plain member differently from memberships api
+	//roles assigned apart from membership are assigned when invitation is accepted
+	inviteRole := role
+	if role == organizationMemberRole || (data.IsOrganizationRole(role) && role != data.BillingManager) {
+		inviteRole = organizationDirectMemberRole
+	}
+
+	jsonBody, err := json.Marshal(struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}{
+		Email: email,
+		Role:  inviteRole,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal body")
+	}
+
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodPost,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/invitations", link),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
+	if helpers.ErrorCodeOf(err) == helpers.ErrorValidationFailed {
+		//email is invited already when job is repeated, so pending invitation is returned
+		invitation, findErr := g.findPendingInvitationByEmail(ctx, link, email, role)
+		if findErr != nil {
+			return nil, errors.Wrap(findErr, "failed to find pending invitation")
+		}
+		if invitation != nil {
+			return invitation, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
+
+	return populateInviteToOrganizationResponse(res, link, role)
+}
+
+func (g *github) findPendingInvitationByEmail(ctx context.Context, link, email, role string) (*data.Invitation, error) {
+	invitations, err := listInvitations[organizationInvitationResponse](ctx, g, link, g.endpoint("/orgs/%s/invitations", link))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pending invitations")
+	}
+
+	for i, invitation := range invitations {
+		if invitation.Email == nil || !strings.EqualFold(*invitation.Email, email) {
+			continue
+		}
+
+		return &data.Invitation{
+			InvitationId: &invitations[i].Id,
+			Username:     stringValue(invitation.Login),
+			Email:        invitation.Email,
+			Link:         link,
+			Type:         data.Organization,
+			AccessLevel:  role,
+			State:        data.InvitationPending,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
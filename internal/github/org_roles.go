@@ -0,0 +1,325 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// getOrganizationMembersFromApi lists members by membership role, roles assigned apart from membership
+// are listed by GetOrganizationRoleUsersFromApi separately
+func (g *github) getOrganizationMembersFromApi(ctx context.Context, link string) ([]data.Permission, error) {
+	result := make([]data.Permission, 0)
+
+	for _, role := range []string{organizationAdminRole, organizationMemberRole} {
+		members, err := g.getUsersFromApi(ctx, link, g.endpoint("/orgs/%s/members", link), map[string]string{"role": role}, data.CollaboratorMember)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to get organization members with `%s` role", role))
+		}
+
+		for _, member := range members {
+			member.AccessLevel = role
+			result = append(result, member)
+		}
+	}
+
+	return result, nil
+}
+
+// assignedOrganizationRoles are granted to members with organization roles endpoints, in order of precedence,
+// billing managers aren't members, they are invited with the role and their membership tells it
+var assignedOrganizationRoles = []string{data.SecurityManager, data.Moderator}
+
+// AssignOrganizationRoleFromApi assigns role that isn't membership role: billing manager is invited with the role,
+// other roles are assigned with organization roles endpoints to members only, so non-member is invited first
+func (g *github) AssignOrganizationRoleFromApi(ctx context.Context, link, username, role string) (*data.Permission, error) {
+	membership, err := g.checkOrganizationMembershipFromApi(ctx, link, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check organization membership")
+	}
+
+	if role == data.BillingManager {
+		if membership != nil && membership.AccessLevel == data.BillingManager {
+			return membership, nil
+		}
+
+		return g.inviteBillingManagerFromApi(ctx, link, username)
+	}
+
+	//role is assigned when invitation is accepted
+	if membership == nil || membership.Invitation != nil {
+		membership, err = g.setOrganizationMembershipFromApi(ctx, link, username, organizationMemberRole)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set organization membership")
+		}
+		if membership == nil {
+			return nil, nil
+		}
+
+		membership.AccessLevel = role
+		if membership.Invitation != nil {
+			return membership, nil
+		}
+	}
+
+	roles, err := g.GetOrganizationRolesFromApi(ctx, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get organization roles")
+	}
+
+	roleId, ok := roles[role]
+	if !ok {
+		return nil, errors.Errorf("role `%s` isn't available in organization `%s`", role, link)
+	}
+
+	err = g.unassignOrganizationRolesFromApi(ctx, link, username, roles, role)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unassign organization roles")
+	}
+
+	err = g.setOrganizationRoleFromApi(ctx, http.MethodPut, link, username, roleId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to assign organization role")
+	}
+
+	membership.AccessLevel = role
+
+	return membership, nil
+}
+
+// RevokeOrganizationRoleFromApi revokes role that isn't membership role, membership of the user is kept,
+// billing manager isn't member, so the membership or pending invitation that gives the role is removed
+func (g *github) RevokeOrganizationRoleFromApi(ctx context.Context, link, username, role string) error {
+	if role == data.BillingManager {
+		return g.revokeBillingManagerFromApi(ctx, link, username)
+	}
+
+	roles, err := g.GetOrganizationRolesFromApi(ctx, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to get organization roles")
+	}
+
+	roleId, ok := roles[role]
+	if !ok {
+		return errors.Errorf("role `%s` isn't available in organization `%s`", role, link)
+	}
+
+	err = g.setOrganizationRoleFromApi(ctx, http.MethodDelete, link, username, roleId)
+	if err != nil {
+		return errors.Wrap(err, "failed to unassign organization role")
+	}
+
+	return nil
+}
+
+func (g *github) revokeBillingManagerFromApi(ctx context.Context, link, username string) error {
+	membership, err := g.checkOrganizationMembershipFromApi(ctx, link, username)
+	if err != nil {
+		return errors.Wrap(err, "failed to check organization membership")
+	}
+	if membership == nil {
+		return errors.Errorf("`%s` isn't billing manager of `%s`", username, link)
+	}
+
+	//member doesn't lose membership role
+	if membership.AccessLevel != data.BillingManager {
+		return nil
+	}
+
+	if membership.Invitation != nil {
+		return g.CancelInvitationFromApi(ctx, link, username, data.Organization, 0)
+	}
+
+	return g.RemoveUserFromApi(ctx, link, username, data.Organization)
+}
+
+// unassignOrganizationRolesFromApi unassigns all roles from organization roles except the kept one
+func (g *github) unassignOrganizationRolesFromApi(ctx context.Context, link, username string, roles map[string]int64, keep string) error {
+	for _, role := range assignedOrganizationRoles {
+		roleId, ok := roles[role]
+		if !ok || role == keep {
+			continue
+		}
+
+		err := g.setOrganizationRoleFromApi(ctx, http.MethodDelete, link, username, roleId)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to unassign `%s` role", role))
+		}
+	}
+
+	return nil
+}
+
+// GetOrganizationRolesFromApi returns ids of roles assigned to members by names, organizations without roles support have none
+func (g *github) GetOrganizationRolesFromApi(ctx context.Context, link string) (map[string]int64, error) {
+	header, err := g.header(readCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodGet,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/organization-roles", link),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	result := make(map[string]int64)
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
+
+	var response struct {
+		Roles []struct {
+			Id   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"roles"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	for _, role := range response.Roles {
+		for _, assigned := range assignedOrganizationRoles {
+			if role.Name == assigned {
+				result[role.Name] = role.Id
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (g *github) GetOrganizationRoleUsersFromApi(ctx context.Context, link string, roleId int64) ([]data.Permission, error) {
+	return g.getUsersFromApi(ctx, link, g.endpoint("/orgs/%s/organization-roles/%d/users", link, roleId), nil, data.CollaboratorMember)
+}
+
+func (g *github) setOrganizationRoleFromApi(ctx context.Context, method, link, username string, roleId int64) error {
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    method,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/organization-roles/users/%s/%d", link, username, roleId),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	_, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//role is unassigned already when organization exists, so repeated job succeeds
+	if helpers.IsNotFound(err) && method == http.MethodDelete {
+		exists, checkErr := g.targetExists(ctx, link, data.Organization)
+		if checkErr != nil {
+			return errors.Wrap(checkErr, "failed to check organization")
+		}
+		if exists {
+			return nil
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	return nil
+}
+
+func (g *github) inviteBillingManagerFromApi(ctx context.Context, link, username string) (*data.Permission, error) {
+	user, err := g.GetUserFromApi(ctx, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user from api")
+	}
+	if user == nil {
+		return nil, errors.Errorf("user `%s` wasn't found", username)
+	}
+
+	jsonBody, err := json.Marshal(struct {
+		InviteeId int64  `json:"invitee_id"`
+		Role      string `json:"role"`
+	}{
+		InviteeId: user.GithubId,
+		Role:      data.BillingManager,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal body")
+	}
+
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodPost,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/invitations", link),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
+
+	invitation, err := populateInviteToOrganizationResponse(res, link, data.BillingManager)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to populate response")
+	}
+
+	return &data.Permission{
+		Link:             link,
+		Username:         user.Username,
+		GithubId:         user.GithubId,
+		AvatarUrl:        user.AvatarUrl,
+		AccessLevel:      data.BillingManager,
+		Type:             data.Organization,
+		CollaboratorKind: data.CollaboratorMember,
+		Invitation:       invitation,
+	}, nil
+}
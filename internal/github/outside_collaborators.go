@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (g *github) GetOutsideCollaboratorsFromApi(ctx context.Context, link string) ([]data.Permission, error) {
+	return g.getUsersFromApi(ctx, link, g.endpoint("/orgs/%s/outside_collaborators", link), nil, data.CollaboratorOutside)
+}
+
+// ConvertMemberToOutsideCollaboratorFromApi removes user from organization and its teams,
+// user keeps access only to repositories granted directly
+func (g *github) ConvertMemberToOutsideCollaboratorFromApi(ctx context.Context, link, username string) error {
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodPut,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/outside_collaborators/%s", link, username),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		//user is converted already when job is repeated
+		converted, checkErr := g.isOutsideCollaboratorFromApi(ctx, link, username)
+		if checkErr != nil {
+			return errors.Wrap(checkErr, "failed to check outside collaborators")
+		}
+		if converted {
+			return nil
+		}
+
+		return errors.Wrap(err, fmt.Sprintf("user `%s` isn't member of `%s`", username, link))
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	return nil
+}
+
+func (g *github) isOutsideCollaboratorFromApi(ctx context.Context, link, username string) (bool, error) {
+	collaborators, err := g.GetOutsideCollaboratorsFromApi(ctx, link)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get outside collaborators")
+	}
+
+	for _, collaborator := range collaborators {
+		if strings.EqualFold(collaborator.Username, username) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
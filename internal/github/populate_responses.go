@@ -18,19 +18,25 @@ func populateAddUserInRepositoryResponse(res *data.ResponseParams) (*data.Permis
 			AvatarUrl string `json:"avatar_url"`
 		} `json:"invitee"`
 		Permissions string `json:"permissions"`
+		Id          int64  `json:"id"`
 	}{}
 
 	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal body")
 	}
 
+	//user gets access only after accepting invitation
 	return &data.Permission{
 		Link:        response.Repository.FullName,
 		Username:    response.Invitee.Login,
 		GithubId:    response.Invitee.Id,
-		AccessLevel: response.Permissions,
+		AccessLevel: data.NormalizeRole(response.Permissions),
 		Type:        data.Repository,
 		AvatarUrl:   response.Invitee.AvatarUrl,
+		Invitation: &data.Invitation{
+			InvitationId: &response.Id,
+			State:        data.InvitationPending,
+		},
 	}, nil
 }
 
@@ -43,7 +49,8 @@ func populateAddUserInOrganizationResponse(res *data.ResponseParams) (*data.Perm
 			Login string `json:"login"`
 			Id    int64  `json:"id"`
 		} `json:"user"`
-		Role string `json:"role"`
+		Role  string `json:"role"`
+		State string `json:"state"`
 	}{}
 
 	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
@@ -51,14 +58,49 @@ func populateAddUserInOrganizationResponse(res *data.ResponseParams) (*data.Perm
 	}
 
 	return &data.Permission{
-		Link:        response.Repository.FullName,
-		Username:    response.Invitee.Login,
-		GithubId:    response.Invitee.Id,
-		AccessLevel: response.Role,
-		Type:        data.Organization,
+		Link:             response.Repository.FullName,
+		Username:         response.Invitee.Login,
+		GithubId:         response.Invitee.Id,
+		AccessLevel:      response.Role,
+		Type:             data.Organization,
+		CollaboratorKind: data.CollaboratorMember,
+		Invitation:       membershipInvitation(response.State),
 	}, nil
 }
 
+func populateInviteToOrganizationResponse(res *data.ResponseParams, link, role string) (*data.Invitation, error) {
+	response := struct {
+		Id    int64   `json:"id"`
+		Login *string `json:"login"`
+		Email *string `json:"email"`
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	return &data.Invitation{
+		InvitationId: &response.Id,
+		Username:     stringValue(response.Login),
+		Email:        response.Email,
+		Link:         link,
+		Type:         data.Organization,
+		AccessLevel:  role,
+		State:        data.InvitationPending,
+	}, nil
+}
+
+// membershipInvitation returns invitation for membership that isn't active yet
+func membershipInvitation(state string) *data.Invitation {
+	if state != membershipPendingState {
+		return nil
+	}
+
+	return &data.Invitation{
+		State: data.InvitationPending,
+	}
+}
+
 func populateCheckRepositoryCollaboratorResponse(res *data.ResponseParams, link, username string) (*data.Permission, error) {
 	response := struct {
 		RoleName string `json:"role_name"`
@@ -83,8 +125,9 @@ func populateCheckRepositoryCollaboratorResponse(res *data.ResponseParams, link,
 
 func populateCheckOrganizationCollaboratorResponse(res *data.ResponseParams, link, username string) (*data.Permission, error) {
 	response := struct {
-		Role string `json:"role"`
-		User struct {
+		Role  string `json:"role"`
+		State string `json:"state"`
+		User  struct {
 			Login string `json:"login"`
 			Id    int64  `json:"id"`
 		} `json:"user"`
@@ -100,6 +143,7 @@ func populateCheckOrganizationCollaboratorResponse(res *data.ResponseParams, lin
 		Username:    username,
 		GithubId:    response.User.Id,
 		AccessLevel: response.Role,
+		Invitation:  membershipInvitation(response.State),
 	}, nil
 }
 
@@ -119,3 +163,56 @@ func populateGetOrganizationResponse(res *data.ResponseParams) (*data.Sub, error
 		Type: data.Organization,
 	}, nil
 }
+
+func populateGetTeamResponse(res *data.ResponseParams, org string) (*data.Sub, error) {
+	var response teamResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	return response.toSub(org), nil
+}
+
+func populateAddUserInTeamResponse(res *data.ResponseParams, link, username string) (*data.Permission, error) {
+	response := struct {
+		Role  string `json:"role"`
+		State string `json:"state"`
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	return &data.Permission{
+		Link:             link,
+		Username:         username,
+		AccessLevel:      response.Role,
+		Type:             data.Team,
+		CollaboratorKind: data.CollaboratorMember,
+		Invitation:       membershipInvitation(response.State),
+	}, nil
+}
+
+type teamResponse struct {
+	Id     int64  `json:"id"`
+	Slug   string `json:"slug"`
+	Parent *struct {
+		Id int64 `json:"id"`
+	} `json:"parent"`
+}
+
+// toSub converts team to sub, parent id is set only for nested teams
+func (t teamResponse) toSub(org string) *data.Sub {
+	sub := data.Sub{
+		Id:   t.Id,
+		Path: t.Slug,
+		Link: teamLink(org, t.Slug),
+		Type: data.Team,
+	}
+
+	if t.Parent != nil {
+		sub.ParentId = &t.Parent.Id
+	}
+
+	return &sub
+}
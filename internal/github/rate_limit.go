@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type rateLimitResponse struct {
+	Resources map[string]struct {
+		Limit     int64 `json:"limit"`
+		Remaining int64 `json:"remaining"`
+		Reset     int64 `json:"reset"`
+	} `json:"resources"`
+}
+
+// ObserveRateLimitsFromApi tells pqueues budget left for every credential, so they pace
+// themselves from the start, requests to `/rate_limit` don't spend budget
+func (g *github) ObserveRateLimitsFromApi(ctx context.Context) error {
+	//empty owner stands for default credentials
+	for _, owner := range append([]string{""}, g.owners...) {
+		for _, cred := range []credential{readCredential, writeCredential} {
+			if err := g.observeRateLimitFromApi(ctx, cred, owner); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to observe rate limit of `%s`", owner))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *github) observeRateLimitFromApi(ctx context.Context, cred credential, owner string) error {
+	header, err := g.header(cred, owner)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:  http.MethodGet,
+		Context: ctx,
+		Link:    g.endpoint("/rate_limit"),
+		Body:    nil,
+		Query:   nil,
+		Header:  header,
+		Timeout: time.Second * 30,
+		Client:  g.httpClient,
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		//rate limiting is disabled on some Enterprise Server instances
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	var response rateLimitResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	observer := g.rateLimit(cred, owner)
+	for resource, budget := range response.Resources {
+		observer.ObserveRateLimit(data.RateLimitStatus{
+			Resource:  resource,
+			Limit:     budget.Limit,
+			Remaining: budget.Remaining,
+			Reset:     time.Unix(budget.Reset, 0),
+		})
+	}
+
+	return nil
+}
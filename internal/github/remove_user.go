@@ -1,7 +1,7 @@
 package github
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"time"
 
@@ -10,23 +10,28 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) RemoveUserFromApi(link, username, typeTo string) error {
-	resultLink := fmt.Sprintf("https://api.github.com/repos/%s/collaborators/%s", link, username)
-	if typeTo == data.Organization {
-		resultLink = fmt.Sprintf("https://api.github.com/orgs/%s/memberships/%s", link, username)
+func (g *github) RemoveUserFromApi(ctx context.Context, link, username, typeTo string) error {
+	resultLink := g.endpoint("/repos/%s/collaborators/%s", link, username)
+	//team link `org/teams/slug` matches team api path
+	if typeTo == data.Organization || typeTo == data.Team {
+		resultLink = g.endpoint("/orgs/%s/memberships/%s", link, username)
+	}
+
+	header, err := g.header(writeCredential, link)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
 	}
 
 	params := data.RequestParams{
-		Method: http.MethodDelete,
-		Link:   resultLink,
-		Body:   nil,
-		Query:  nil,
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Method:    http.MethodDelete,
+		Context:   ctx,
+		Link:      resultLink,
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, link),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -34,13 +39,20 @@ func (g *github) RemoveUserFromApi(link, username, typeTo string) error {
 		return errors.Wrap(err, "failed to make http request")
 	}
 
-	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	_, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//user is removed already when target exists, so repeated job succeeds
+	if helpers.IsNotFound(err) {
+		exists, checkErr := g.targetExists(ctx, link, typeTo)
+		if checkErr != nil {
+			return errors.Wrap(checkErr, "failed to check removal target")
+		}
+		if exists {
+			return nil
+		}
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return errors.Errorf("error in response, status %v", res.StatusCode)
-	}
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,20 +11,25 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) SearchByFromApi(username string) ([]data.User, error) {
+func (g *github) SearchByFromApi(ctx context.Context, username string) ([]data.User, error) {
+	header, err := g.header(readCredential, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
 	params := data.RequestParams{
-		Method: http.MethodGet,
-		Link:   "https://api.github.com/search/users",
-		Body:   nil,
+		Method:  http.MethodGet,
+		Context: ctx,
+		Link:    g.endpoint("/search/users"),
+		Body:    nil,
 		Query: map[string]string{
 			"q": username + " in:login",
 		},
-		Header: map[string]string{
-			"Accept":               data.AcceptHeader,
-			"Authorization":        "Bearer " + g.superUserToken,
-			"X-GitHub-Api-Version": data.GithubApiVersionHeader,
-		},
-		Timeout: time.Second * 30,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, ""),
 	}
 
 	res, err := helpers.MakeHttpRequest(params)
@@ -35,9 +41,6 @@ func (g *github) SearchByFromApi(username string) ([]data.User, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to check response status code")
 	}
-	if res == nil {
-		return nil, errors.Errorf("error in response, status %v", res.StatusCode)
-	}
 
 	var response struct {
 		Items []data.User `json:"items"`
@@ -0,0 +1,148 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type teamRepositoryResponse struct {
+	FullName string `json:"full_name"`
+	RoleName string `json:"role_name"`
+}
+
+func (g *github) GetTeamRepositoriesFromApi(ctx context.Context, teamLink string) ([]data.TeamPermission, error) {
+	header, err := g.header(readCredential, teamLink)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	repositories, err := helpers.CollectPages[teamRepositoryResponse](data.RequestParams{
+		Method:  http.MethodGet,
+		Context: ctx,
+		Link:    g.endpoint("/orgs/%s/repos", teamLink),
+		Body:    nil,
+		Query: map[string]string{
+			"per_page": "100",
+		},
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		Cache:     g.cache,
+		RateLimit: g.rateLimit(readCredential, teamLink),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request with pagination")
+	}
+
+	result := make([]data.TeamPermission, len(repositories))
+	for i, repository := range repositories {
+		result[i] = data.TeamPermission{
+			TeamLink:    teamLink,
+			RepoLink:    repository.FullName,
+			AccessLevel: repository.RoleName,
+		}
+	}
+
+	return result, nil
+}
+
+func (g *github) AddOrUpdateTeamInRepositoryFromApi(ctx context.Context, teamLink, repoLink, permission string) (*data.TeamPermission, error) {
+	jsonBody, err := json.Marshal(struct {
+		Permission string `json:"permission"`
+	}{
+		Permission: permission,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal body")
+	}
+
+	header, err := g.header(writeCredential, teamLink)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodPut,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/repos/%s", teamLink, repoLink),
+		Body:      jsonBody,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, teamLink),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make http request")
+	}
+
+	//typed not found error keeps its code, so processor reports it as failure code
+	_, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check response status code")
+	}
+
+	return &data.TeamPermission{
+		TeamLink:    teamLink,
+		RepoLink:    repoLink,
+		AccessLevel: permission,
+	}, nil
+}
+
+func (g *github) RemoveTeamFromRepositoryFromApi(ctx context.Context, teamLink, repoLink string) error {
+	header, err := g.header(writeCredential, teamLink)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request header")
+	}
+
+	params := data.RequestParams{
+		Method:    http.MethodDelete,
+		Context:   ctx,
+		Link:      g.endpoint("/orgs/%s/repos/%s", teamLink, repoLink),
+		Body:      nil,
+		Query:     nil,
+		Header:    header,
+		Timeout:   time.Second * 30,
+		Client:    g.httpClient,
+		RateLimit: g.rateLimit(writeCredential, teamLink),
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to make http request")
+	}
+
+	_, err = helpers.HandleHttpResponseStatusCode(res, params)
+	//team has no access already when both team and repository exist, so repeated job succeeds
+	if helpers.IsNotFound(err) {
+		exists, checkErr := g.teamAndRepositoryExist(ctx, teamLink, repoLink)
+		if checkErr != nil {
+			return errors.Wrap(checkErr, "failed to check team and repository")
+		}
+		if exists {
+			return nil
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check response status code")
+	}
+
+	return nil
+}
+
+func (g *github) teamAndRepositoryExist(ctx context.Context, teamLink, repoLink string) (bool, error) {
+	exists, err := g.targetExists(ctx, teamLink, data.Team)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return g.targetExists(ctx, repoLink, data.Repository)
+}
@@ -0,0 +1,299 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/config"
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"github.com/golang-jwt/jwt/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// GitHub rejects app jwt that lives longer than 10 minutes
+	appJwtLifetime = 9 * time.Minute
+	// installation tokens live one hour, refresh them a bit earlier to avoid expiring in flight
+	installationTokenRefreshGap = 5 * time.Minute
+	// unknownOwnerTtl is how long owner without installation isn't looked up again
+	unknownOwnerTtl = 10 * time.Minute
+)
+
+type tokenSource interface {
+	Token(owner string) (string, error)
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(_ string) (string, error) {
+	return string(s), nil
+}
+
+// ownerTokenSource routes owners with their own credentials away from default token source
+type ownerTokenSource struct {
+	defaultTokens tokenSource
+	owners        map[string]tokenSource
+}
+
+func (s ownerTokenSource) Token(owner string) (string, error) {
+	tokens, ok := s.owners[strings.ToLower(owner)]
+	if !ok {
+		return s.defaultTokens.Token(owner)
+	}
+
+	return tokens.Token(owner)
+}
+
+type installationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// appTokenSource authenticates as GitHub App and hands out installation token of link owner,
+// network calls are made without holding mu, so slow GitHub doesn't block tokens of other installations
+type appTokenSource struct {
+	app    config.GithubApp
+	client *github
+
+	mu            sync.Mutex
+	installations map[string]int64
+	// unknown keeps owners without installation until they are looked up again
+	unknown map[string]time.Time
+	tokens  map[int64]installationToken
+	// refreshing serializes token creation per installation, so concurrent callers make one request
+	refreshing map[int64]*sync.Mutex
+
+	// loading serializes listing of installations, so concurrent misses make one request
+	loading sync.Mutex
+}
+
+func newAppTokenSource(app config.GithubApp, client *github) *appTokenSource {
+	return &appTokenSource{
+		app:           app,
+		client:        client,
+		installations: make(map[string]int64),
+		unknown:       make(map[string]time.Time),
+		tokens:        make(map[int64]installationToken),
+		refreshing:    make(map[int64]*sync.Mutex),
+	}
+}
+
+func (s *appTokenSource) Token(owner string) (string, error) {
+	installationId, err := s.getInstallationId(strings.ToLower(owner))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get installation id")
+	}
+
+	if token, ok := s.cachedToken(installationId); ok {
+		return token, nil
+	}
+
+	refreshing := s.refreshLock(installationId)
+	refreshing.Lock()
+	defer refreshing.Unlock()
+
+	//token could be created while waiting for lock
+	if token, ok := s.cachedToken(installationId); ok {
+		return token, nil
+	}
+
+	token, err := s.createInstallationToken(installationId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create installation token")
+	}
+
+	s.mu.Lock()
+	s.tokens[installationId] = token
+	s.mu.Unlock()
+
+	return token.token, nil
+}
+
+func (s *appTokenSource) cachedToken(installationId int64) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[installationId]
+	if !ok || time.Until(token.expiresAt) <= installationTokenRefreshGap {
+		return "", false
+	}
+
+	return token.token, true
+}
+
+func (s *appTokenSource) refreshLock(installationId int64) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.refreshing[installationId]
+	if !ok {
+		lock = new(sync.Mutex)
+		s.refreshing[installationId] = lock
+	}
+
+	return lock
+}
+
+// getInstallationId returns installation of owner, calls that aren't bound to any owner use default one
+func (s *appTokenSource) getInstallationId(owner string) (int64, error) {
+	if owner == "" {
+		owner = s.app.DefaultOwner
+	}
+	if owner == "" {
+		return 0, errors.New("call isn't bound to owner and github app has no default owner")
+	}
+
+	installationId, found, err := s.knownInstallationId(owner)
+	if found || err != nil {
+		return installationId, err
+	}
+
+	s.loading.Lock()
+	defer s.loading.Unlock()
+
+	//installations could be listed while waiting for lock
+	installationId, found, err = s.knownInstallationId(owner)
+	if found || err != nil {
+		return installationId, err
+	}
+
+	//app could be installed in new account since last time
+	installations, err := s.loadInstallations()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to load installations")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for login, id := range installations {
+		s.installations[login] = id
+		delete(s.unknown, login)
+	}
+
+	installationId, ok := s.installations[owner]
+	if !ok {
+		s.unknown[owner] = time.Now().Add(unknownOwnerTtl)
+		return 0, errors.Errorf("github app isn't installed for `%s`", owner)
+	}
+
+	return installationId, nil
+}
+
+// knownInstallationId looks owner up without requests, owners that were recently not found are reported as such
+func (s *appTokenSource) knownInstallationId(owner string) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if installationId, ok := s.installations[owner]; ok {
+		return installationId, true, nil
+	}
+
+	if time.Now().Before(s.unknown[owner]) {
+		return 0, false, errors.Errorf("github app isn't installed for `%s`", owner)
+	}
+
+	return 0, false, nil
+}
+
+type installationResponse struct {
+	Id      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+	} `json:"account"`
+}
+
+func (s *appTokenSource) loadInstallations() (map[string]int64, error) {
+	header, err := s.appHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build app header")
+	}
+
+	installations, err := helpers.CollectPages[installationResponse](data.RequestParams{
+		Method: http.MethodGet,
+		Link:   s.client.endpoint("/app/installations"),
+		Body:   nil,
+		Query: map[string]string{
+			"per_page": "100",
+		},
+		Header:  header,
+		Timeout: time.Second * 30,
+		Client:  s.client.httpClient,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request with pagination")
+	}
+
+	result := make(map[string]int64, len(installations))
+	for _, installation := range installations {
+		result[strings.ToLower(installation.Account.Login)] = installation.Id
+	}
+
+	return result, nil
+}
+
+func (s *appTokenSource) createInstallationToken(installationId int64) (installationToken, error) {
+	header, err := s.appHeader()
+	if err != nil {
+		return installationToken{}, errors.Wrap(err, "failed to build app header")
+	}
+
+	params := data.RequestParams{
+		Method:  http.MethodPost,
+		Link:    s.client.endpoint("/app/installations/%d/access_tokens", installationId),
+		Body:    nil,
+		Query:   nil,
+		Header:  header,
+		Timeout: time.Second * 30,
+		Client:  s.client.httpClient,
+	}
+
+	res, err := helpers.MakeHttpRequest(params)
+	if err != nil {
+		return installationToken{}, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = helpers.HandleHttpResponseStatusCode(res, params)
+	if helpers.IsNotFound(err) {
+		return installationToken{}, errors.Wrap(err, fmt.Sprintf("installation `%d` wasn't found", installationId))
+	}
+	if err != nil {
+		return installationToken{}, errors.Wrap(err, "failed to check response status code")
+	}
+
+	var response struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return installationToken{}, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	return installationToken{
+		token:     response.Token,
+		expiresAt: response.ExpiresAt,
+	}, nil
+}
+
+// appHeader authenticates request as the app itself with short-lived jwt
+func (s *appTokenSource) appHeader() (map[string]string, error) {
+	now := time.Now()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		//protects from clock drift between module and GitHub
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appJwtLifetime)),
+		Issuer:    strconv.FormatInt(s.app.Id, 10),
+	}).SignedString(s.app.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign app jwt")
+	}
+
+	return s.client.tokenHeader(signed), nil
+}
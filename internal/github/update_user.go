@@ -1,25 +1,28 @@
 package github
 
 import (
+	"context"
 	"github.com/acs-dl/github-module-svc/internal/data"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (g *github) UpdateUserFromApi(typeTo, link, username, permission string) (*data.Permission, error) {
+func (g *github) UpdateUserFromApi(ctx context.Context, typeTo, link, username, permission string) (*data.Permission, error) {
 	switch typeTo {
 	case data.Repository:
-		owned, err := g.FindRepositoryOwner(link)
+		owned, err := g.FindRepositoryOwner(ctx, link)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to check if repository owner")
 		}
 
 		if owned == data.UserOwned {
-			permission = "write"
+			permission = data.RoleWrite
 		}
 
-		return g.AddOrUpdateUserInRepositoryFromApi(link, username, permission)
+		return g.AddOrUpdateUserInRepositoryFromApi(ctx, link, username, permission)
 	case data.Organization:
-		return g.AddOrUpdateUserInOrganizationFromApi(link, username, permission)
+		return g.AddOrUpdateUserInOrganizationFromApi(ctx, link, username, permission)
+	case data.Team:
+		return g.AddOrUpdateUserInTeamFromApi(ctx, link, username, permission)
 	default:
 		return nil, errors.Errorf("unexpected type `%s`", typeTo)
 	}
@@ -0,0 +1,196 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// ErrorCode classifies failed GitHub requests, it is sent to orchestrator in responses
+type ErrorCode string
+
+const (
+	ErrorNotFound             ErrorCode = "not_found"
+	ErrorUnauthorized         ErrorCode = "unauthorized"
+	ErrorSsoRequired          ErrorCode = "sso_required"
+	ErrorForbidden            ErrorCode = "forbidden"
+	ErrorRateLimited          ErrorCode = "rate_limited"
+	ErrorSecondaryRateLimited ErrorCode = "secondary_rate_limited"
+	ErrorValidationFailed     ErrorCode = "validation_failed"
+	ErrorServerError          ErrorCode = "server_error"
+	ErrorUnexpectedResponse   ErrorCode = "unexpected_response"
+	// ErrorInternal is used for failures that didn't come from GitHub
+	ErrorInternal ErrorCode = "internal_error"
+)
+
+// ValidationField describes rejected field of 422 response
+type ValidationField struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// GithubError is error response of GitHub API
+type GithubError struct {
+	Code             ErrorCode
+	StatusCode       int
+	Message          string
+	DocumentationUrl string
+	Fields           []ValidationField
+	// RetryAfter is set for rate limited requests
+	RetryAfter time.Duration
+}
+
+func (e *GithubError) Error() string {
+	message := fmt.Sprintf("github responded `%s` (%s)", http.StatusText(e.StatusCode), e.Code)
+	if e.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Message)
+	}
+
+	for _, field := range e.Fields {
+		message = fmt.Sprintf("%s; %s.%s %s", message, field.Resource, field.Field, field.Code)
+		if field.Message != "" {
+			message = fmt.Sprintf("%s (%s)", message, field.Message)
+		}
+	}
+
+	return message
+}
+
+// Cooldown tells pqueue to repeat rate limited request after returned duration
+func (e *GithubError) Cooldown() (time.Duration, bool) {
+	if e.Code != ErrorRateLimited && e.Code != ErrorSecondaryRateLimited {
+		return 0, false
+	}
+
+	return e.RetryAfter, true
+}
+
+// CredentialWide tells pqueue that secondary rate limit is shared by all resources of credential
+func (e *GithubError) CredentialWide() bool {
+	return e.Code == ErrorSecondaryRateLimited
+}
+
+// AsGithubError returns GitHub error wrapped into err if there is one
+func AsGithubError(err error) *GithubError {
+	if err == nil {
+		return nil
+	}
+
+	githubErr, ok := errors.Cause(err).(*GithubError)
+	if !ok {
+		return nil
+	}
+
+	return githubErr
+}
+
+// ErrorCodeOf classifies err, errors that didn't come from GitHub are internal
+func ErrorCodeOf(err error) ErrorCode {
+	githubErr := AsGithubError(err)
+	if githubErr == nil {
+		return ErrorInternal
+	}
+
+	return githubErr.Code
+}
+
+func IsNotFound(err error) bool {
+	githubErr := AsGithubError(err)
+	return githubErr != nil && githubErr.Code == ErrorNotFound
+}
+
+func IsRateLimited(err error) bool {
+	githubErr := AsGithubError(err)
+	return githubErr != nil && (githubErr.Code == ErrorRateLimited || githubErr.Code == ErrorSecondaryRateLimited)
+}
+
+type githubErrorResponse struct {
+	Message          string            `json:"message"`
+	DocumentationUrl string            `json:"documentation_url"`
+	Errors           []json.RawMessage `json:"errors"`
+}
+
+// newGithubError builds error from response, body is consumed
+func newGithubError(response *data.ResponseParams) *GithubError {
+	result := &GithubError{
+		Code:       classifyResponse(response),
+		StatusCode: response.StatusCode,
+	}
+
+	if response.Body != nil {
+		var body githubErrorResponse
+		raw, err := io.ReadAll(response.Body)
+		if err == nil && json.Unmarshal(raw, &body) == nil {
+			result.Message = body.Message
+			result.DocumentationUrl = body.DocumentationUrl
+			result.Fields = parseValidationFields(body.Errors)
+		}
+		if result.Code == ErrorForbidden && isSecondaryRateLimitMessage(result.Message) {
+			result.Code = ErrorSecondaryRateLimited
+		}
+	}
+
+	if result.Code == ErrorRateLimited || result.Code == ErrorSecondaryRateLimited {
+		result.RetryAfter, _ = GetDuration(response.Header)
+	}
+
+	return result
+}
+
+func classifyResponse(response *data.ResponseParams) ErrorCode {
+	switch status := response.StatusCode; {
+	case status == http.StatusNotFound:
+		return ErrorNotFound
+	case status == http.StatusUnauthorized:
+		return ErrorUnauthorized
+	case status == http.StatusForbidden && strings.HasPrefix(response.Header.Get("X-GitHub-SSO"), "required"):
+		return ErrorSsoRequired
+	case (status == http.StatusForbidden || status == http.StatusTooManyRequests) && response.Header.Get("x-ratelimit-remaining") == "0":
+		return ErrorRateLimited
+	case (status == http.StatusForbidden || status == http.StatusTooManyRequests) && response.Header.Get("Retry-After") != "":
+		return ErrorSecondaryRateLimited
+	case status == http.StatusTooManyRequests:
+		return ErrorSecondaryRateLimited
+	case status == http.StatusForbidden:
+		return ErrorForbidden
+	case status == http.StatusUnprocessableEntity:
+		return ErrorValidationFailed
+	case status >= http.StatusInternalServerError:
+		return ErrorServerError
+	default:
+		return ErrorUnexpectedResponse
+	}
+}
+
+func isSecondaryRateLimitMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+// parseValidationFields reads `errors` of 422 response, some endpoints return plain strings there
+func parseValidationFields(rawErrors []json.RawMessage) []ValidationField {
+	result := make([]ValidationField, 0, len(rawErrors))
+
+	for _, raw := range rawErrors {
+		var field ValidationField
+		if err := json.Unmarshal(raw, &field); err == nil {
+			result = append(result, field)
+			continue
+		}
+
+		var message string
+		if err := json.Unmarshal(raw, &message); err == nil {
+			result = append(result, ValidationField{Message: message})
+		}
+	}
+
+	return result
+}
@@ -3,8 +3,8 @@ package helpers
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"log"
 	"net/http"
@@ -21,7 +21,12 @@ func MakeHttpRequest(params data.RequestParams) (*data.ResponseParams, error) {
 		return nil, errors.Wrap(err, "couldn't create request")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), params.Timeout)
+	parent := params.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, params.Timeout)
 	defer cancel()
 	req = req.WithContext(ctx)
 
@@ -39,7 +44,22 @@ func MakeHttpRequest(params data.RequestParams) (*data.ResponseParams, error) {
 		req.URL.RawQuery = q.Encode()
 	}
 
-	response, err := http.DefaultClient.Do(req)
+	client := http.DefaultClient
+	if params.Client != nil {
+		client = params.Client
+	}
+
+	cached := getCachedResponse(req, params.Cache)
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	response, err := client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "error making http request")
 	}
@@ -49,6 +69,42 @@ func MakeHttpRequest(params data.RequestParams) (*data.ResponseParams, error) {
 		return nil, errors.Wrap(err, "error reading response body")
 	}
 
+	//every request is charged, so call that makes several requests is paced as several calls
+	if params.RateLimit != nil {
+		if status, ok := RateLimitFromHeader(response.Header); ok {
+			params.RateLimit.ObserveRateLimit(status)
+		}
+		params.RateLimit.ObserveRequest(rateLimitResource(response.Header), response.StatusCode != http.StatusNotModified)
+	}
+
+	//not modified responses aren't counted against rate limit, they may omit link header of cached page
+	if cached != nil && response.StatusCode == http.StatusNotModified {
+		storeCachedResponse(params.Cache, *cached)
+
+		header := response.Header.Clone()
+		header.Del("Link")
+		if cached.Link != "" {
+			header.Set("Link", cached.Link)
+		}
+
+		return &data.ResponseParams{
+			Body:       io.NopCloser(bytes.NewReader(cached.Body)),
+			Header:     header,
+			StatusCode: http.StatusOK,
+		}, nil
+	}
+
+	if req.Method == http.MethodGet && params.Cache != nil && response.StatusCode == http.StatusOK {
+		storeCachedResponse(params.Cache, data.CachedResponse{
+			Url:          req.URL.String(),
+			Credential:   cacheCredential(req),
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+			Link:         response.Header.Get("Link"),
+			Body:         body,
+		})
+	}
+
 	return &data.ResponseParams{
 		Body:       io.NopCloser(bytes.NewReader(body)),
 		Header:     response.Header,
@@ -56,36 +112,81 @@ func MakeHttpRequest(params data.RequestParams) (*data.ResponseParams, error) {
 	}, nil
 }
 
-func HandleHttpResponseStatusCode(response *data.ResponseParams, params data.RequestParams) (*data.ResponseParams, error) {
-	switch status := response.StatusCode; {
-	case status >= http.StatusOK && status < http.StatusMultipleChoices:
+// getCachedResponse returns last full response for GET request made with the same credential
+func getCachedResponse(req *http.Request, cache data.HttpCache) *data.CachedResponse {
+	if cache == nil || req.Method != http.MethodGet {
+		return nil
+	}
+
+	cached, err := cache.New().FilterByUrls(req.URL.String()).FilterByCredentials(cacheCredential(req)).Get()
+	if err != nil {
+		log.Printf("failed to get cached response: %s", err)
+		return nil
+	}
+
+	return cached
+}
+
+// storeCachedResponse saves response that has validators, cache failures don't break requests
+func storeCachedResponse(cache data.HttpCache, response data.CachedResponse) {
+	if response.ETag == "" && response.LastModified == "" {
+		return
+	}
+
+	if err := cache.New().Upsert(response); err != nil {
+		log.Printf("failed to store cached response: %s", err)
+	}
+}
+
+// cacheCredential identifies token without storing it
+func cacheCredential(req *http.Request) string {
+	hash := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(hash[:])
+}
+
+// HandleHttpResponseStatusCode returns successful response or *GithubError,
+// rate limited requests aren't repeated here, pqueue pauses and repeats them
+func HandleHttpResponseStatusCode(response *data.ResponseParams, _ data.RequestParams) (*data.ResponseParams, error) {
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
 		return response, nil
-	case status == http.StatusNotFound:
-		return nil, nil
-	case status == http.StatusForbidden:
-		return HandleTooManyRequests(response.Header, params)
-	case status < http.StatusOK || status >= http.StatusMultipleChoices:
-		return nil, errors.New(fmt.Sprintf("error in response `%s`", http.StatusText(response.StatusCode)))
-	default:
-		return nil, errors.New(fmt.Sprintf("unexpected response status `%s`", http.StatusText(response.StatusCode)))
 	}
+
+	return nil, newGithubError(response)
 }
 
-func HandleTooManyRequests(header http.Header, params data.RequestParams) (*data.ResponseParams, error) {
-	//If you exceed the rate limit, the response will have a 403 status and the x-ratelimit-remaining header will be 0
-	if header.Get("x-ratelimit-remaining ") != "0" {
-		return nil, errors.New("request is forbidden")
+// RateLimitFromHeader reads budget that GitHub reports with every response
+func RateLimitFromHeader(header http.Header) (data.RateLimitStatus, bool) {
+	limit, err := strconv.ParseInt(header.Get("x-ratelimit-limit"), 10, 64)
+	if err != nil {
+		return data.RateLimitStatus{}, false
+	}
+
+	remaining, err := strconv.ParseInt(header.Get("x-ratelimit-remaining"), 10, 64)
+	if err != nil {
+		return data.RateLimitStatus{}, false
 	}
 
-	timeoutDuration, err := GetDuration(header)
+	reset, err := strconv.ParseInt(header.Get("x-ratelimit-reset"), 10, 64)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get time duration from response")
+		return data.RateLimitStatus{}, false
 	}
 
-	log.Printf("we need to wait `%s`", timeoutDuration.String())
-	time.Sleep(timeoutDuration)
+	return data.RateLimitStatus{
+		Resource:  rateLimitResource(header),
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+// rateLimitResource returns resource that request spent, responses without it are counted against core one
+func rateLimitResource(header http.Header) string {
+	resource := header.Get("x-ratelimit-resource")
+	if resource == "" {
+		return data.CoreRateLimit
+	}
 
-	return MakeHttpRequest(params)
+	return resource
 }
 
 func GetDuration(header http.Header) (time.Duration, error) {
@@ -94,7 +195,7 @@ func GetDuration(header http.Header) (time.Duration, error) {
 		if err != nil {
 			return 0, errors.Wrap(err, "failed to parse `retry-after `header")
 		}
-		return time.Duration(durationInSeconds), nil
+		return time.Duration(durationInSeconds) * time.Second, nil
 	}
 
 	if header.Get("x-ratelimit-reset") != "" {
@@ -113,37 +214,3 @@ func GetDuration(header http.Header) (time.Duration, error) {
 
 	return 0, errors.New("failed to retrieve time from headers")
 }
-
-func MakeRequestWithPagination(params data.RequestParams) ([]byte, error) {
-	result := make([]interface{}, 0)
-
-	for page := 1; ; page++ {
-		params.Query["page"] = strconv.Itoa(page)
-
-		res, err := MakeHttpRequest(params)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to make http request")
-		}
-
-		res, err = HandleHttpResponseStatusCode(res, params)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to check response status code")
-		}
-		if res == nil {
-			return nil, errors.Errorf("error in response, status %v", res.StatusCode)
-		}
-
-		var response []interface{}
-		if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
-			return nil, errors.Wrap(err, "failed to unmarshal body")
-		}
-
-		if len(response) == 0 {
-			break
-		}
-
-		result = append(result, response...)
-	}
-
-	return json.Marshal(result)
-}
@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Paginate requests pages following `Link: rel="next"` header (RFC 5988) and passes every decoded page to handle,
+// so the caller doesn't have to keep all pages in memory
+func Paginate[T any](params data.RequestParams, handle func(page []T) error) error {
+	for {
+		page, err := GetPage[T](params)
+		if err != nil {
+			return err
+		}
+
+		if len(page.Items) != 0 {
+			if err = handle(page.Items); err != nil {
+				return errors.Wrap(err, "failed to handle page")
+			}
+		}
+
+		if page.Next == "" {
+			return nil
+		}
+
+		//next link already contains all query params
+		params.Link = page.Next
+		params.Query = nil
+	}
+}
+
+// GetPage requests one page and returns it with link of the next one, so pages can be requested one by one
+func GetPage[T any](params data.RequestParams) (data.Page[T], error) {
+	res, err := MakeHttpRequest(params)
+	if err != nil {
+		return data.Page[T]{}, errors.Wrap(err, "failed to make http request")
+	}
+
+	res, err = HandleHttpResponseStatusCode(res, params)
+	if err != nil {
+		return data.Page[T]{}, errors.Wrap(err, "failed to check response status code")
+	}
+
+	var items []T
+	if err = json.NewDecoder(res.Body).Decode(&items); err != nil {
+		return data.Page[T]{}, errors.Wrap(err, "failed to unmarshal body")
+	}
+
+	return data.Page[T]{
+		Items: items,
+		Next:  nextPageLink(res.Header),
+	}, nil
+}
+
+// CollectPages returns items of all pages for small lists
+func CollectPages[T any](params data.RequestParams) ([]T, error) {
+	result := make([]T, 0)
+
+	err := Paginate(params, func(page []T) error {
+		result = append(result, page...)
+		return nil
+	})
+
+	return result, err
+}
+
+// nextPageLink finds link with `next` relation in `<url>; rel="next", <url>; rel="last"` like header
+func nextPageLink(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+
+	return ""
+}
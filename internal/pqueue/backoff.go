@@ -0,0 +1,64 @@
+package pqueue
+
+import (
+	"math/rand"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// RetryableError is returned by calls that hit rate limit,
+// queue is paused for cooldown before such call is repeated
+type RetryableError interface {
+	error
+	Cooldown() (time.Duration, bool)
+}
+
+// CredentialWideError is retryable error of limit that isn't bound to one resource, e.g. GitHub secondary rate limit,
+// every bucket of queue credential is paused after it
+type CredentialWideError interface {
+	RetryableError
+	CredentialWide() bool
+}
+
+// Backoff tells how long queue is paused after rate limited call
+type Backoff struct {
+	MaxRetries int
+	Initial    time.Duration
+	Max        time.Duration
+}
+
+// delay returns exponential backoff with jitter for attempt,
+// cooldown requested by server is waited even if it is longer than Max
+func (b Backoff) delay(attempt int, cooldown time.Duration) time.Duration {
+	exponential := b.Initial << attempt
+	if exponential <= 0 || exponential > b.Max {
+		exponential = b.Max
+	}
+
+	//half of delay is random, so paused queues don't retry at the same moment
+	delay := exponential / 2
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)))
+	}
+
+	if cooldown > delay {
+		return cooldown
+	}
+
+	return delay
+}
+
+func cooldownOf(err error) (time.Duration, bool) {
+	retryable, ok := errors.Cause(err).(RetryableError)
+	if !ok {
+		return 0, false
+	}
+
+	return retryable.Cooldown()
+}
+
+func isCredentialWide(err error) bool {
+	wide, ok := errors.Cause(err).(CredentialWideError)
+	return ok && wide.CredentialWide()
+}
@@ -0,0 +1,184 @@
+package pqueue
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SuperUserQueue = "super_user"
+	UserQueue      = "user"
+)
+
+var (
+	// ErrItemNotPending is returned for item that is called already or isn't in queue at all
+	ErrItemNotPending = errors.New("item isn't waiting in queue")
+	// ErrCancelled is returned to producers of item cancelled by operator
+	ErrCancelled = errors.New("call was cancelled by operator")
+	// ErrUnknownPriority is returned for priority that isn't one of priority classes
+	ErrUnknownPriority = errors.New("priority isn't one of priority classes")
+)
+
+// Priorities are priority classes items are queued with
+var Priorities = []int{HighPriority, NormalPriority, LowPriority}
+
+// QueuedItem describes item waiting in queue
+type QueuedItem struct {
+	// Sequence identifies item in its queue
+	Sequence   uint64
+	Method     string
+	Args       []string
+	Resource   string
+	Priority   int
+	EnqueuedAt time.Time
+	Amount     int
+	// Position is place of item in order of aged priorities starting from 1,
+	// pace of resources and shares of classes can make dispatcher call it earlier
+	Position int
+}
+
+// Items returns pending items in order they are going to be called
+func (pq *PriorityQueue) Items() []QueuedItem {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	pending := make([]*QueueItem, 0)
+	for _, items := range pq.pending {
+		pending = append(pending, *items...)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].before(pending[j], pq.rateLimit.Aging)
+	})
+
+	result := make([]QueuedItem, len(pending))
+	for i, item := range pending {
+		result[i] = QueuedItem{
+			Sequence:   item.sequence,
+			Method:     item.Method,
+			Args:       append([]string(nil), item.Args...),
+			Resource:   item.Resource,
+			Priority:   item.Priority,
+			EnqueuedAt: item.EnqueuedAt,
+			Amount:     item.Amount,
+			Position:   i + 1,
+		}
+	}
+
+	return result
+}
+
+// SetPriority moves pending item to another priority class, it keeps its enqueue time
+func (pq *PriorityQueue) SetPriority(sequence uint64, priority int) error {
+	if !isPriority(priority) {
+		return ErrUnknownPriority
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	item := pq.pendingItem(sequence)
+	if item == nil {
+		return ErrItemNotPending
+	}
+
+	pq.move(item, priority)
+
+	return nil
+}
+
+// Cancel drops pending item, its producers get ErrCancelled and the next producer with the same key queues new item
+func (pq *PriorityQueue) Cancel(sequence uint64) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	item := pq.pendingItem(sequence)
+	if item == nil {
+		return ErrItemNotPending
+	}
+
+	heap.Remove(pq.pending[item.lane()], item.index)
+	delete(pq.items, item.Id)
+
+	item.Response = Response{Error: ErrCancelled}
+	item.invoked = INVOKED
+	close(item.done)
+	item.cancel()
+
+	return nil
+}
+
+func isPriority(priority int) bool {
+	for _, class := range Priorities {
+		if class == priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pq *PriorityQueue) pendingItem(sequence uint64) *QueueItem {
+	for _, item := range pq.items {
+		if item.sequence == sequence && item.index >= 0 {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// Pause stops calling pending items until Resume, item that is being called is finished
+func (pq *PriorityQueue) Pause() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	pq.paused = true
+}
+
+func (pq *PriorityQueue) Resume() {
+	pq.mu.Lock()
+	pq.paused = false
+	pq.mu.Unlock()
+
+	select {
+	case pq.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (pq *PriorityQueue) Paused() bool {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	return pq.paused
+}
+
+func (cq *CredentialPQueues) queues() map[string]*PriorityQueue {
+	return map[string]*PriorityQueue{
+		SuperUserQueue: cq.SuperUserPQueue,
+		UserQueue:      cq.UserPQueue,
+	}
+}
+
+// Queues returns all queues by name: queues of default credentials are named `super_user` and `user`,
+// names of owner queues are prefixed with owner, e.g. `my-org:user`
+func (pqs *PQueues) Queues() map[string]*PriorityQueue {
+	result := pqs.CredentialPQueues.queues()
+	for owner, queues := range pqs.owners {
+		for name, queue := range queues.queues() {
+			result[fmt.Sprintf("%s:%s", owner, name)] = queue
+		}
+	}
+
+	return result
+}
+
+// Queue returns queue by name that Queues reports
+func (pqs *PQueues) Queue(name string) (*PriorityQueue, bool) {
+	queue, ok := pqs.Queues()[strings.ToLower(name)]
+	return queue, ok
+}
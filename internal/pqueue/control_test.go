@@ -0,0 +1,159 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancel(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	var calls int64
+	key := NewKey("AddUserFromApi", "org", "username")
+	call := func(context.Context) (string, error) {
+		atomic.AddInt64(&calls, 1)
+		return "added", nil
+	}
+
+	cancelled := submitAsync(t, queue, key, NormalPriority, call)
+
+	items := queue.Items()
+	if len(items) != 1 {
+		t.Fatalf("expected one pending item, got %d", len(items))
+	}
+
+	if err := queue.Cancel(items[0].Sequence); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := <-cancelled; err != ErrCancelled {
+		t.Fatalf("expected ErrCancelled, got %v", err)
+	}
+	if err := queue.Cancel(items[0].Sequence); err != ErrItemNotPending {
+		t.Fatalf("expected ErrItemNotPending for cancelled item, got %v", err)
+	}
+
+	//the next producer with the same key queues new item
+	repeated := submitAsync(t, queue, key, NormalPriority, call)
+	queue.Resume()
+
+	if err := <-repeated; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if atomic.LoadInt64(&calls) != 1 {
+		t.Fatalf("expected only repeated item to be called, got %d calls", calls)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	var calls int64
+	result := submitAsync(t, queue, NewKey("GetTeamsFromApi", "org"), LowPriority, func(context.Context) (string, error) {
+		atomic.AddInt64(&calls, 1)
+		return "", nil
+	})
+
+	time.Sleep(3 * idleInterval)
+	if atomic.LoadInt64(&calls) != 0 {
+		t.Fatal("paused queue called item")
+	}
+	if queue.Len() != 1 {
+		t.Fatalf("expected one pending item, got %d", queue.Len())
+	}
+
+	queue.Resume()
+
+	if err := <-result; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if atomic.LoadInt64(&calls) != 1 {
+		t.Fatalf("expected one call, got %d", calls)
+	}
+}
+
+func TestConcurrentControl(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+
+	const producers = 30
+
+	results := make(chan error, producers)
+	for i := 0; i < producers; i++ {
+		go func(i int) {
+			_, err := Submit(context.Background(), queue, NewKey("CheckUserFromApi", fmt.Sprint(i)), func(context.Context) (int, error) {
+				time.Sleep(time.Millisecond)
+				return i, nil
+			}, LowPriority)
+			results <- err
+		}(i)
+	}
+
+	//operator controls queue while dispatcher calls items
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 20; i++ {
+			queue.Pause()
+			for _, item := range queue.Items() {
+				_ = queue.SetPriority(item.Sequence, HighPriority)
+				if item.Sequence%2 == 0 {
+					_ = queue.Cancel(item.Sequence)
+				}
+			}
+			queue.Resume()
+		}
+	}()
+
+	<-done
+	for i := 0; i < producers; i++ {
+		if err := <-results; err != nil && err != ErrCancelled {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	waitFor(t, func() bool { return queue.Len() == 0 })
+}
+
+func TestSetPriority(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	log := &callLog{}
+	high := submitAsync(t, queue, NewKey("high"), HighPriority, log.call("high"))
+	low := submitAsync(t, queue, NewKey("low"), LowPriority, log.call("low"))
+
+	var lowSequence uint64
+	for _, item := range queue.Items() {
+		if item.Method == "low" {
+			lowSequence = item.Sequence
+		}
+	}
+
+	if err := queue.SetPriority(lowSequence, 7); err != ErrUnknownPriority {
+		t.Fatalf("expected ErrUnknownPriority, got %v", err)
+	}
+	if err := queue.SetPriority(lowSequence, HighPriority); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	items := queue.Items()
+	if len(items) != 2 || items[1].Method != "low" || items[1].Priority != HighPriority {
+		t.Fatalf("expected raised item after earlier one of the same class, got %+v", items)
+	}
+
+	queue.Resume()
+
+	for _, result := range []<-chan error{high, low} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := queue.SetPriority(lowSequence, NormalPriority); err != ErrItemNotPending {
+		t.Fatalf("expected ErrItemNotPending for called item, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package pqueue
+
+// itemHeap keeps pending items of one lane in order they were added,
+// repeated rate limited item keeps its place
+type itemHeap []*QueueItem
+
+func (h itemHeap) Len() int { return len(h) }
+
+func (h itemHeap) Less(i, j int) bool {
+	return h[i].sequence < h[j].sequence
+}
+
+func (h itemHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *itemHeap) Push(x interface{}) {
+	item := x.(*QueueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[0 : n-1]
+
+	return item
+}
+
+func (h itemHeap) peek() *QueueItem {
+	if len(h) == 0 {
+		return nil
+	}
+
+	return h[0]
+}
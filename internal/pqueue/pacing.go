@@ -0,0 +1,176 @@
+package pqueue
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+)
+
+const (
+	// minInterval keeps bursts below GitHub secondary rate limit of concurrent-like traffic
+	minInterval = 100 * time.Millisecond
+	// idleInterval is how often queue is checked when there was nothing to call
+	idleInterval = 100 * time.Millisecond
+)
+
+// ResourceLimit is budget of rate limit resource that isn't shared with core one, e.g. `search`
+type ResourceLimit struct {
+	RequestsAmount int64
+	TimeLimit      time.Duration
+}
+
+// bucket paces calls of one rate limit resource, configured limit is used until GitHub reports budget,
+// it is paused on its own, so e.g. exhausted search budget doesn't stop sync
+type bucket struct {
+	mu sync.Mutex
+
+	static time.Duration
+	// reserve is share of budget spent only by HighPriority calls
+	reserve float64
+	status  *data.RateLimitStatus
+
+	// nextCall is the earliest time the next call may start, every request made by calls moves it further
+	nextCall time.Time
+	// pausedUntil is set when rate limit is hit, no item is called before it
+	pausedUntil time.Time
+}
+
+func newBucket(limit ResourceLimit, reserve float64) *bucket {
+	static := minInterval
+	if limit.RequestsAmount > 0 {
+		static = limit.TimeLimit / time.Duration(limit.RequestsAmount)
+	}
+
+	return &bucket{
+		static:  static,
+		reserve: reserve,
+	}
+}
+
+// buckets keeps bucket per rate limit resource of one budget, they are created on demand
+type buckets struct {
+	mu         sync.Mutex
+	rateLimit  RateLimit
+	byResource map[string]*bucket
+	// pausedUntil is set when credential wide limit is hit, no bucket is called before it
+	pausedUntil time.Time
+}
+
+func newBuckets(rateLimit RateLimit) *buckets {
+	return &buckets{
+		rateLimit:  rateLimit,
+		byResource: make(map[string]*bucket),
+	}
+}
+
+func (bs *buckets) get(resource string) *bucket {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	resourceBucket, ok := bs.byResource[resource]
+	if !ok {
+		resourceBucket = newBucket(bs.rateLimit.resourceLimit(resource), bs.rateLimit.HighPriorityReserve)
+		bs.byResource[resource] = resourceBucket
+	}
+
+	return resourceBucket
+}
+
+// wait returns how long every bucket has to wait after credential wide limit
+func (bs *buckets) wait(now time.Time) time.Duration {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if now.Before(bs.pausedUntil) {
+		return bs.pausedUntil.Sub(now)
+	}
+
+	return 0
+}
+
+func (bs *buckets) pause(cooldown time.Duration) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.pausedUntil = time.Now().Add(cooldown)
+}
+
+func (b *bucket) observe(status data.RateLimitStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.status = &status
+}
+
+// wait returns how long call of priority has to wait for bucket: calls burst when budget is plentiful,
+// slow down near exhaustion and only HighPriority ones are made from reserve
+func (b *bucket) wait(now time.Time, priority int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	_, minPriority := b.next(now)
+	if priority < minPriority {
+		return idleInterval
+	}
+
+	return b.nextCall.Sub(now)
+}
+
+// next returns interval between calls and the lowest priority that may spend budget now
+func (b *bucket) next(now time.Time) (time.Duration, int) {
+	//budget is restored after reset, so configured pace is used until GitHub reports new one
+	if b.status == nil || !now.Before(b.status.Reset) {
+		return b.static, LowPriority
+	}
+
+	window := b.status.Reset.Sub(now)
+	reserved := int64(math.Ceil(float64(b.status.Limit) * b.reserve))
+
+	if available := b.status.Remaining - reserved; available > 0 {
+		return atLeastMin(window / time.Duration(available)), LowPriority
+	}
+
+	if b.status.Remaining > 0 {
+		return atLeastMin(window / time.Duration(b.status.Remaining)), HighPriority
+	}
+
+	return window, HighPriority
+}
+
+// charge moves the next call by pace interval of one request made at now,
+// request that isn't counted against budget is paced by minInterval only
+func (b *bucket) charge(now time.Time, counted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	interval := minInterval
+	if counted {
+		interval, _ = b.next(now)
+	}
+
+	if b.nextCall.Before(now) {
+		b.nextCall = now
+	}
+	b.nextCall = b.nextCall.Add(interval)
+}
+
+func (b *bucket) pause(cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pausedUntil = time.Now().Add(cooldown)
+}
+
+func atLeastMin(interval time.Duration) time.Duration {
+	if interval < minInterval {
+		return minInterval
+	}
+
+	return interval
+}
@@ -0,0 +1,220 @@
+package pqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+)
+
+// rateLimitedError is what GitHub client returns for rate limited request
+type rateLimitedError struct {
+	cooldown time.Duration
+	wide     bool
+}
+
+func (e rateLimitedError) Error() string {
+	return "rate limited"
+}
+
+func (e rateLimitedError) Cooldown() (time.Duration, bool) {
+	return e.cooldown, true
+}
+
+func (e rateLimitedError) CredentialWide() bool {
+	return e.wide
+}
+
+// rateLimitedOnce fails the first call with rate limit error and records when every call is made
+type rateLimitedOnce struct {
+	mu    sync.Mutex
+	err   error
+	calls []time.Time
+}
+
+func (r *rateLimitedOnce) call(context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, time.Now())
+	if len(r.calls) == 1 {
+		return "", r.err
+	}
+
+	return "done", nil
+}
+
+func (r *rateLimitedOnce) get() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]time.Time(nil), r.calls...)
+}
+
+// calledAt records when call is made
+type calledAt struct {
+	mu sync.Mutex
+	at time.Time
+}
+
+func (c *calledAt) call(context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.at = time.Now()
+	return "done", nil
+}
+
+func (c *calledAt) get() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.at
+}
+
+var testBackoff = Backoff{
+	MaxRetries: 1,
+	Initial:    time.Millisecond,
+	Max:        time.Millisecond,
+}
+
+func TestBucketPauseAndResume(t *testing.T) {
+	resourceBucket := newBucket(ResourceLimit{}, 0)
+
+	now := time.Now()
+	if wait := resourceBucket.wait(now, LowPriority); wait > 0 {
+		t.Fatalf("expected fresh bucket to allow call, got wait %s", wait)
+	}
+
+	resourceBucket.pause(50 * time.Millisecond)
+	if wait := resourceBucket.wait(time.Now(), HighPriority); wait <= 0 {
+		t.Fatal("expected paused bucket to make even high priority call wait")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if wait := resourceBucket.wait(time.Now(), LowPriority); wait > 0 {
+		t.Fatalf("expected bucket to resume after cooldown, got wait %s", wait)
+	}
+}
+
+func TestBucketKeepsReserveForHighPriority(t *testing.T) {
+	resourceBucket := newBucket(ResourceLimit{}, 0.1)
+
+	now := time.Now()
+	resourceBucket.observe(data.RateLimitStatus{
+		Resource:  data.CoreRateLimit,
+		Limit:     100,
+		Remaining: 5,
+		Reset:     now.Add(time.Hour),
+	})
+
+	if wait := resourceBucket.wait(now, LowPriority); wait != idleInterval {
+		t.Fatalf("expected low priority call to wait for reserve, got wait %s", wait)
+	}
+	if wait := resourceBucket.wait(now, HighPriority); wait > 0 {
+		t.Fatalf("expected high priority call to spend reserve, got wait %s", wait)
+	}
+
+	//charged request moves the next call by pace of the rest budget
+	resourceBucket.charge(now, true)
+	if wait := resourceBucket.wait(now, HighPriority); wait < time.Hour/5-time.Second {
+		t.Fatalf("expected charged bucket to pace next call, got wait %s", wait)
+	}
+}
+
+func TestRateLimitedCallPausesOnlyItsBucket(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{Backoff: testBackoff})
+	queue.Pause()
+
+	const cooldown = 300 * time.Millisecond
+
+	limited := &rateLimitedOnce{err: rateLimitedError{cooldown: cooldown}}
+	other := &calledAt{}
+
+	limitedResult := submitAsync(t, queue, NewKey("GetUserFromApi", "username"), HighPriority, limited.call)
+	otherResult := submitAsync(t, queue, NewKey("SearchByFromApi", "username").Spending(data.SearchRateLimit), LowPriority, other.call)
+
+	queue.Resume()
+
+	for _, result := range []<-chan error{limitedResult, otherResult} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	calls := limited.get()
+	if len(calls) != 2 {
+		t.Fatalf("expected rate limited call to be repeated once, got %d calls", len(calls))
+	}
+	if calls[1].Sub(calls[0]) < cooldown {
+		t.Fatalf("expected call to be repeated after cooldown, it was repeated in %s", calls[1].Sub(calls[0]))
+	}
+	if !other.get().Before(calls[1]) {
+		t.Fatal("expected call of another resource to be made while bucket is paused")
+	}
+}
+
+func TestCredentialWideRateLimitPausesAllBuckets(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{Backoff: testBackoff})
+	queue.Pause()
+
+	const cooldown = 300 * time.Millisecond
+
+	limited := &rateLimitedOnce{err: rateLimitedError{cooldown: cooldown, wide: true}}
+	other := &calledAt{}
+
+	limitedResult := submitAsync(t, queue, NewKey("AddUserFromApi", "org", "username"), HighPriority, limited.call)
+	otherResult := submitAsync(t, queue, NewKey("SearchByFromApi", "username").Spending(data.SearchRateLimit), LowPriority, other.call)
+
+	queue.Resume()
+
+	for _, result := range []<-chan error{limitedResult, otherResult} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	calls := limited.get()
+	if len(calls) != 2 {
+		t.Fatalf("expected rate limited call to be repeated once, got %d calls", len(calls))
+	}
+	if other.get().Sub(calls[0]) < cooldown {
+		t.Fatalf("expected call of another resource to wait for cooldown, it waited %s", other.get().Sub(calls[0]))
+	}
+}
+
+func TestSharedBudgetPausesBothQueues(t *testing.T) {
+	queues := newCredentialPQueues(RateLimit{Backoff: testBackoff, SharedBudget: true})
+
+	stop := make(chan struct{})
+	queues.processQueues(stop)
+	t.Cleanup(func() { close(stop) })
+
+	queues.SuperUserPQueue.Pause()
+	queues.UserPQueue.Pause()
+
+	const cooldown = 300 * time.Millisecond
+
+	limited := &rateLimitedOnce{err: rateLimitedError{cooldown: cooldown}}
+	other := &calledAt{}
+
+	limitedResult := submitAsync(t, queues.SuperUserPQueue, NewKey("AddUserFromApi", "org", "username"), HighPriority, limited.call)
+	queues.SuperUserPQueue.Resume()
+	waitFor(t, func() bool { return len(limited.get()) == 1 })
+
+	otherResult := submitAsync(t, queues.UserPQueue, NewKey("GetUserFromApi", "username"), HighPriority, other.call)
+	queues.UserPQueue.Resume()
+
+	for _, result := range []<-chan error{limitedResult, otherResult} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	calls := limited.get()
+	if other.get().Sub(calls[0]) < cooldown/2 {
+		t.Fatalf("expected queue of shared budget to wait for paused bucket, it waited %s", other.get().Sub(calls[0]))
+	}
+}
@@ -1,129 +1,334 @@
 package pqueue
 
 import (
+	"container/heap"
 	"context"
 	"errors"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/acs-dl/github-module-svc/internal/data"
 	"github.com/acs-dl/github-module-svc/internal/service/background"
 )
 
 type PriorityQueueInterface interface {
-	WaitUntilInvoked(id string) (*QueueItem, error)
-	ProcessQueue(requestLimit int64, timeLimit time.Duration, stop chan struct{})
+	WaitUntilInvoked(ctx context.Context, id string) (*QueueItem, error)
+	ProcessQueue(stop chan struct{})
+	ObserveRateLimit(status data.RateLimitStatus)
+	ObserveRequest(resource string, counted bool)
 }
 
-type PQueues struct {
+type RateLimit struct {
+	RequestsAmount int64
+	TimeLimit      time.Duration
+	Backoff        Backoff
+	// HighPriorityReserve is share of budget left for HighPriority calls, e.g. 0.1
+	HighPriorityReserve float64
+	// Resources are limits of resources that have own budget, the rest are paced as core one until GitHub reports them
+	Resources map[string]ResourceLimit
+	// Aging is wait time that raises priority of queued call by one, zero disables aging
+	Aging time.Duration
+	// Shares are guaranteed shares of calls per priority, e.g. 0.1 for LowPriority
+	Shares map[int]float64
+	// SharedBudget tells that super user and user queues spend one budget, e.g. of GitHub App installation,
+	// so they are paced by the same buckets
+	SharedBudget bool
+}
+
+func (r RateLimit) resourceLimit(resource string) ResourceLimit {
+	if limit, ok := r.Resources[resource]; ok {
+		return limit
+	}
+
+	return ResourceLimit{
+		RequestsAmount: r.RequestsAmount,
+		TimeLimit:      r.TimeLimit,
+	}
+}
+
+// CredentialPQueues holds queue per credential, so every queue paces requests against its own rate limit budget
+type CredentialPQueues struct {
+	// SuperUserPQueue spends admin token budget, it is only for mutations
 	SuperUserPQueue *PriorityQueue
-	UserPQueue      *PriorityQueue
+	// UserPQueue spends usual token budget, it is for read-only calls
+	UserPQueue *PriorityQueue
+}
+
+func newCredentialPQueues(rateLimit RateLimit) *CredentialPQueues {
+	superUserBuckets, userBuckets := newBuckets(rateLimit), newBuckets(rateLimit)
+	if rateLimit.SharedBudget {
+		userBuckets = superUserBuckets
+	}
+
+	return &CredentialPQueues{
+		SuperUserPQueue: newPriorityQueue(rateLimit, superUserBuckets),
+		UserPQueue:      newPriorityQueue(rateLimit, userBuckets),
+	}
+}
+
+func (cq *CredentialPQueues) processQueues(stop chan struct{}) {
+	go cq.SuperUserPQueue.ProcessQueue(stop)
+	go cq.UserPQueue.ProcessQueue(stop)
+}
+
+func (cq *CredentialPQueues) Len() int {
+	return cq.SuperUserPQueue.Len() + cq.UserPQueue.Len()
 }
 
-func NewPQueues() PQueues {
+func (cq *CredentialPQueues) WaitTimes() []WaitTimes {
+	return mergeWaitTimes(cq.SuperUserPQueue.WaitTimes(), cq.UserPQueue.WaitTimes())
+}
+
+// PQueues embeds queues of default credentials and keeps separate ones
+// for owners that have their own credentials, so one owner can't exhaust budget of another
+type PQueues struct {
+	*CredentialPQueues
+	owners map[string]*CredentialPQueues
+}
+
+// NewPQueues creates queues of default credentials and of owners that have their own ones
+func NewPQueues(rateLimit RateLimit, ownersRateLimits map[string]RateLimit) PQueues {
+	owners := make(map[string]*CredentialPQueues)
+	for owner, ownerRateLimit := range ownersRateLimits {
+		owners[strings.ToLower(owner)] = newCredentialPQueues(ownerRateLimit)
+	}
+
 	return PQueues{
-		SuperUserPQueue: NewPriorityQueue().(*PriorityQueue),
-		UserPQueue:      NewPriorityQueue().(*PriorityQueue),
+		CredentialPQueues: newCredentialPQueues(rateLimit),
+		owners:            owners,
+	}
+}
+
+// ForOwner returns queues bound to credentials of owner
+func (pqs *PQueues) ForOwner(owner string) *CredentialPQueues {
+	queues, ok := pqs.owners[strings.ToLower(owner)]
+	if !ok {
+		return pqs.CredentialPQueues
 	}
+
+	return queues
 }
 
+// ForLink returns queues bound to credentials of `owner/repo` like link owner
+func (pqs *PQueues) ForLink(link string) *CredentialPQueues {
+	return pqs.ForOwner(strings.Split(link, "/")[0])
+}
+
+func (pqs *PQueues) ProcessQueues(stop chan struct{}) {
+	pqs.CredentialPQueues.processQueues(stop)
+	for _, queues := range pqs.owners {
+		queues.processQueues(stop)
+	}
+}
+
+// Len returns amount of items waiting in all queues
+func (pqs *PQueues) Len() int {
+	amount := pqs.CredentialPQueues.Len()
+	for _, queues := range pqs.owners {
+		amount += queues.Len()
+	}
+
+	return amount
+}
+
+// WaitTimes returns wait time histograms per priority class summed over all queues
+func (pqs *PQueues) WaitTimes() []WaitTimes {
+	histograms := [][]WaitTimes{pqs.CredentialPQueues.WaitTimes()}
+	for _, queues := range pqs.owners {
+		histograms = append(histograms, queues.WaitTimes())
+	}
+
+	return mergeWaitTimes(histograms...)
+}
+
+// PriorityQueue is safe for concurrent use: producers add items from any goroutine,
+// the only dispatcher started by ProcessQueue calls them one by one
 type PriorityQueue struct {
-	queueArray []*QueueItem
-	queueMap   sync.Map
+	mu sync.Mutex
+	// pending keeps items waiting to be called per rate limit resource and priority
+	pending map[lane]*itemHeap
+	// items keeps pending and called items until all their producers release them
+	items map[string]*QueueItem
+	// sequence orders items of the same priority
+	sequence uint64
+	// wake tells dispatcher about new item, so it doesn't wait for the next poll
+	wake chan struct{}
+	// history keeps classes of the latest calls to give every class its share
+	history *dispatchHistory
+	// waitTimes are histograms of time called items waited per priority
+	waitTimes map[int]*WaitTimes
+	// paused queue doesn't call pending items, the one being called is finished
+	paused bool
+
+	backoff   Backoff
+	rateLimit RateLimit
+	// buckets could be shared with queue of another credential that spends the same budget
+	buckets *buckets
+}
+
+func NewPriorityQueue(rateLimit RateLimit) PriorityQueueInterface {
+	return newPriorityQueue(rateLimit, newBuckets(rateLimit))
 }
 
-func NewPriorityQueue() PriorityQueueInterface {
+func newPriorityQueue(rateLimit RateLimit, buckets *buckets) *PriorityQueue {
 	return &PriorityQueue{
-		queueArray: make([]*QueueItem, 0),
+		pending:   make(map[lane]*itemHeap),
+		items:     make(map[string]*QueueItem),
+		wake:      make(chan struct{}, 1),
+		history:   newDispatchHistory(),
+		waitTimes: make(map[int]*WaitTimes),
+		backoff:   rateLimit.Backoff,
+		rateLimit: rateLimit,
+		buckets:   buckets,
 	}
 }
 
-func (pq *PriorityQueue) Len() int { return len(pq.queueArray) }
+// ObserveRateLimit adjusts pace of resource bucket to budget reported by GitHub for queue credential
+func (pq *PriorityQueue) ObserveRateLimit(status data.RateLimitStatus) {
+	pq.bucket(status.Resource).observe(status)
+}
 
-func (pq *PriorityQueue) Less(i, j int) bool {
-	return pq.queueArray[i].Priority > pq.queueArray[j].Priority
+// ObserveRequest charges resource bucket for request made by called item
+func (pq *PriorityQueue) ObserveRequest(resource string, counted bool) {
+	pq.bucket(resource).charge(time.Now(), counted)
 }
 
-func (pq *PriorityQueue) Swap(i, j int) {
-	pq.queueArray[i], pq.queueArray[j] = pq.queueArray[j], pq.queueArray[i]
-	pq.queueArray[i].index = i
-	pq.queueArray[j].index = j
+func (pq *PriorityQueue) bucket(resource string) *bucket {
+	return pq.buckets.get(resource)
 }
 
-func (pq *PriorityQueue) Push(x interface{}) {
-	item := x.(*QueueItem)
+// Len returns amount of items waiting to be called
+func (pq *PriorityQueue) Len() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	amount := 0
+	for _, items := range pq.pending {
+		amount += items.Len()
+	}
+
+	return amount
+}
 
-	pqMapItem, exists := pq.queueMap.Load(item.Id)
-	if exists {
-		pqItem, ok := pqMapItem.(*QueueItem)
-		if !ok {
-			panic("failed to convert map element")
+// Add queues item and returns the one that will be called for it,
+// items with the same id share one call, every Add has to be followed by RemoveById
+func (pq *PriorityQueue) Add(item *QueueItem) *QueueItem {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if queued, exists := pq.items[item.Id]; exists {
+		queued.Amount++
+		//pending item is called with the highest priority of its producers
+		if item.Priority > queued.Priority && queued.index >= 0 {
+			pq.move(queued, item.Priority)
 		}
-		pqItem.Amount++
-		return
+		return queued
+	}
+
+	if len(item.Resources) == 0 {
+		item.Resources = []string{data.CoreRateLimit}
 	}
+	item.Resource = item.Resources[0]
 
-	n := len(pq.queueArray)
-	item.index = n
+	pq.sequence++
+	item.sequence = pq.sequence
+	item.EnqueuedAt = time.Now()
 	item.invoked = PROCESSING
+	item.done = make(chan struct{})
+	//call outlives producer contexts, it is cancelled only when nobody waits for its result
+	item.ctx, item.cancel = context.WithCancel(context.Background())
 	item.Amount++
-	pq.queueArray = append(pq.queueArray, item)
-	pq.queueMap.Store(item.Id, item)
-}
 
-func (pq *PriorityQueue) Pop() interface{} {
-	old := pq.queueArray
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	pq.queueArray = old[0 : n-1]
-	pq.queueMap.Delete(item.Id)
+	pq.items[item.Id] = item
+	pq.push(item)
+
+	select {
+	case pq.wake <- struct{}{}:
+	default:
+	}
 
 	return item
 }
 
-func (pq *PriorityQueue) RemoveById(id string) error {
-	item, err := pq.getElement(id)
-	if err != nil {
-		return err
+func (pq *PriorityQueue) push(item *QueueItem) {
+	items, ok := pq.pending[item.lane()]
+	if !ok {
+		items = &itemHeap{}
+		pq.pending[item.lane()] = items
 	}
 
-	if item.Amount > 1 {
-		item.Amount--
-		return nil
+	heap.Push(items, item)
+}
+
+// move has to be called with locked mu for pending item, it moves item to lane of another priority
+func (pq *PriorityQueue) move(item *QueueItem, priority int) {
+	heap.Remove(pq.pending[item.lane()], item.index)
+	item.Priority = priority
+	pq.push(item)
+
+	select {
+	case pq.wake <- struct{}{}:
+	default:
 	}
+}
 
-	pq.queueArray = append(pq.queueArray[:item.index], pq.queueArray[item.index+1:]...)
-	pq.queueMap.Delete(item.Id)
+// RemoveById releases item for one producer, item is forgotten when the last one releases it,
+// so item that wasn't called yet is dropped without spending budget
+func (pq *PriorityQueue) RemoveById(id string) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	item, exists := pq.items[id]
+	if !exists {
+		return errors.New("element not found")
+	}
+
+	pq.release(item)
 
-	pq.FixIndexesInPQueue()
 	return nil
 }
 
-func (pq *PriorityQueue) FixIndexesInPQueue() {
-	for i, queueItem := range pq.queueArray {
-		if queueItem.index != i {
-			queueItem.index = i
-		}
+// release has to be called with locked mu, item could be cancelled and forgotten by queue already
+func (pq *PriorityQueue) release(item *QueueItem) {
+	if item.Amount > 1 {
+		item.Amount--
+		return
+	}
+
+	item.Amount = 0
+	if item.index >= 0 {
+		heap.Remove(pq.pending[item.lane()], item.index)
+	}
+	if pq.items[item.Id] == item {
+		delete(pq.items, item.Id)
 	}
+	item.cancel()
+}
+
+// Release releases item returned by Add for one producer
+func (pq *PriorityQueue) Release(item *QueueItem) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	pq.release(item)
 }
 
 func (pq *PriorityQueue) getElement(id string) (*QueueItem, error) {
-	pqMapItem, exists := pq.queueMap.Load(id)
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	item, exists := pq.items[id]
 	if !exists {
 		return nil, errors.New("element not found")
 	}
 
-	pqItem, ok := pqMapItem.(*QueueItem)
-	if !ok {
-		return nil, errors.New("failed to convert map element")
-	}
-
-	return pqItem, nil
+	return item, nil
 }
 
-func (pq *PriorityQueue) WaitUntilInvoked(id string) (*QueueItem, error) {
+// WaitUntilInvoked waits for item result until ctx is done, producer still has to release item after it
+func (pq *PriorityQueue) WaitUntilInvoked(ctx context.Context, id string) (*QueueItem, error) {
 	log.Printf("waiting until invoked for `%s`", id)
 
 	item, err := pq.getElement(id)
@@ -131,39 +336,160 @@ func (pq *PriorityQueue) WaitUntilInvoked(id string) (*QueueItem, error) {
 		return nil, err
 	}
 
-	item.waitInvoked()
+	if err = item.waitInvoked(ctx); err != nil {
+		return nil, err
+	}
 
 	return item, nil
 }
 
-func (pq *PriorityQueue) ProcessQueue(requestLimit int64, timeLimit time.Duration, stop chan struct{}) {
-	ticker := time.NewTicker(timeLimit / time.Duration(requestLimit))
-	defer ticker.Stop()
+func (pq *PriorityQueue) ProcessQueue(stop chan struct{}) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			pq.processNextItem()
+		case <-timer.C:
+		case <-pq.wake:
+			if !timer.Stop() {
+				<-timer.C
+			}
 		case <-stop:
 			return
 		}
+
+		timer.Reset(pq.processNextItem())
+	}
+}
+
+// processNextItem calls the most prioritized item that pace of its buckets allows
+// and returns delay before next attempt
+func (pq *PriorityQueue) processNextItem() time.Duration {
+	now := time.Now()
+	item, next := pq.popNextItem(now)
+	if item == nil {
+		return next
+	}
+
+	cooldown, wide, retry := item.callFunction(pq.backoff)
+	if !retry {
+		//time spent in backoff is counted too, it is what producers waited
+		pq.observeWait(item.Priority, now.Sub(item.EnqueuedAt))
+		return 0
+	}
+
+	if wide {
+		pq.buckets.pause(cooldown)
+		log.Printf("credential wide rate limit is hit by `%s`, all buckets are paused for `%s`", item.Id, cooldown.String())
+	} else {
+		//it isn't known which of declared resources was exhausted
+		for _, resource := range item.Resources {
+			pq.bucket(resource).pause(cooldown)
+		}
+		log.Printf("rate limit is hit by `%s`, `%s` buckets are paused for `%s`", item.Id, strings.Join(item.Resources, ", "), cooldown.String())
+	}
+
+	pq.mu.Lock()
+	//all producers could leave while item was called
+	if pq.items[item.Id] == item {
+		pq.push(item)
 	}
+	pq.mu.Unlock()
+
+	return 0
 }
 
-func (pq *PriorityQueue) processNextItem() {
-	for i := 0; i < pq.Len(); i++ {
-		item := pq.queueArray[i]
-		if item == nil {
+// popNextItem takes top items of lanes which buckets allow a call and pops the one of class that got less than
+// its share or the most prioritized one, delay before next attempt is returned when there is no such item
+func (pq *PriorityQueue) popNextItem(now time.Time) (*QueueItem, time.Duration) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.paused {
+		return nil, idleInterval
+	}
+	if wait := pq.buckets.wait(now); wait > 0 {
+		return nil, wait
+	}
+
+	var next, starving *QueueItem
+	var starvingDeficit float64
+	delay := idleInterval
+
+	for _, items := range pq.pending {
+		top := items.peek()
+		if top == nil {
 			continue
 		}
 
-		if item.invoked == INVOKED {
+		if wait := pq.wait(now, top); wait > 0 {
+			if wait < delay {
+				delay = wait
+			}
 			continue
 		}
 
-		item.callFunction()
-		return
+		deficit := pq.history.deficit(top.Priority, pq.rateLimit.Shares[top.Priority])
+		if deficit > 0 && (starving == nil || deficit > starvingDeficit ||
+			deficit == starvingDeficit && top.before(starving, pq.rateLimit.Aging)) {
+			starving, starvingDeficit = top, deficit
+		}
+
+		if next == nil || top.before(next, pq.rateLimit.Aging) {
+			next = top
+		}
+	}
+
+	if next == nil {
+		return nil, delay
+	}
+	if starving != nil {
+		next = starving
+	}
+
+	//bucket is charged by requests that item makes, not by the call itself
+	heap.Pop(pq.pending[next.lane()])
+	pq.history.record(next.Priority)
+
+	return next, 0
+}
+
+// wait returns how long item has to wait for buckets of all resources it spends
+func (pq *PriorityQueue) wait(now time.Time, item *QueueItem) time.Duration {
+	var result time.Duration
+	for _, resource := range item.Resources {
+		if wait := pq.bucket(resource).wait(now, item.Priority); wait > result {
+			result = wait
+		}
+	}
+
+	return result
+}
+
+func (pq *PriorityQueue) observeWait(priority int, wait time.Duration) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	waitTimes, ok := pq.waitTimes[priority]
+	if !ok {
+		waitTimes = newWaitTimes(priority)
+		pq.waitTimes[priority] = waitTimes
+	}
+
+	waitTimes.observe(wait)
+}
+
+// WaitTimes returns histograms of time items of every priority class waited before they were called
+func (pq *PriorityQueue) WaitTimes() []WaitTimes {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	result := make([]WaitTimes, 0, len(pq.waitTimes))
+	for _, waitTimes := range pq.waitTimes {
+		result = append(result, waitTimes.copy())
 	}
+
+	return mergeWaitTimes(result)
 }
 
 func PQueuesInstance(ctx context.Context) *PQueues {
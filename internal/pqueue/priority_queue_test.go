@@ -0,0 +1,152 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T, rateLimit RateLimit) *PriorityQueue {
+	t.Helper()
+
+	queue := newPriorityQueue(rateLimit, newBuckets(rateLimit))
+
+	stop := make(chan struct{})
+	go queue.ProcessQueue(stop)
+	t.Cleanup(func() { close(stop) })
+
+	return queue
+}
+
+// waitFor polls condition, queue is driven by its own goroutine, so its state is checked until it settles
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition wasn't met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// submitAsync submits call from its own goroutine and waits until queue has one more pending item,
+// so items of the test are queued in known order
+func submitAsync(t *testing.T, queue *PriorityQueue, key Key, priority int, call func(context.Context) (string, error)) <-chan error {
+	t.Helper()
+
+	pending := queue.Len()
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := Submit(context.Background(), queue, key, call, priority)
+		result <- err
+	}()
+
+	waitFor(t, func() bool { return queue.Len() == pending+1 })
+
+	return result
+}
+
+// callLog records order of calls made by dispatcher
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) call(name string) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.calls = append(l.calls, name)
+		return name, nil
+	}
+}
+
+func (l *callLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.calls...)
+}
+
+func TestProcessQueueDispatchOrder(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	log := &callLog{}
+	results := []<-chan error{
+		submitAsync(t, queue, NewKey("low"), LowPriority, log.call("low")),
+		submitAsync(t, queue, NewKey("normal-1"), NormalPriority, log.call("normal-1")),
+		submitAsync(t, queue, NewKey("high"), HighPriority, log.call("high")),
+		submitAsync(t, queue, NewKey("normal-2"), NormalPriority, log.call("normal-2")),
+	}
+
+	queue.Resume()
+
+	for _, result := range results {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := []string{"high", "normal-1", "normal-2", "low"}
+	if calls := log.get(); fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestProcessQueueDispatchOrderWithAging(t *testing.T) {
+	//low priority item waited for more than its distance to high priority
+	queue := newTestQueue(t, RateLimit{Aging: time.Millisecond})
+	queue.Pause()
+
+	log := &callLog{}
+	low := submitAsync(t, queue, NewKey("low"), LowPriority, log.call("low"))
+	time.Sleep(20 * time.Millisecond)
+	high := submitAsync(t, queue, NewKey("high"), HighPriority, log.call("high"))
+
+	queue.Resume()
+
+	for _, result := range []<-chan error{low, high} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := []string{"low", "high"}
+	if calls := log.get(); fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestProcessQueueGivesShares(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{Shares: map[int]float64{LowPriority: 0.5}})
+	queue.Pause()
+
+	log := &callLog{}
+	results := make([]<-chan error, 0)
+	for i := 0; i < 2; i++ {
+		name := fmt.Sprintf("high-%d", i)
+		results = append(results, submitAsync(t, queue, NewKey(name), HighPriority, log.call(name)))
+	}
+	results = append(results, submitAsync(t, queue, NewKey("low"), LowPriority, log.call("low")))
+
+	queue.Resume()
+
+	for _, result := range results {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	//low class got nothing of its share after the first call
+	expected := []string{"high-0", "low", "high-1"}
+	if calls := log.get(); fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+}
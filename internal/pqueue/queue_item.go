@@ -1,8 +1,8 @@
 package pqueue
 
 import (
-	"reflect"
-	"sync"
+	"context"
+	"time"
 )
 
 type ItemStatus string
@@ -23,65 +23,81 @@ type Response struct {
 type QueueItem struct {
 	Id string
 
-	Func     interface{}
-	Args     []interface{}
+	// Method and Args describe call for humans, Id is the one that identifies it
+	Method   string
+	Args     []string
 	Response Response
 
+	// Resource is the main rate limit resource which budget call spends, it is the first of Resources
+	Resource string
+	// Resources are all rate limit resources call spends, core one when none is declared
+	Resources []string
+
 	Priority int
-	Amount   int
+	// EnqueuedAt is when the first producer added item, item priority is aged since then
+	EnqueuedAt time.Time
+	// Amount is number of producers waiting for item, it is guarded by queue
+	Amount int
+	// index is position in heap of pending items, -1 when item isn't pending
 	index    int
+	sequence uint64
 	invoked  ItemStatus
-
-	mu   sync.Mutex
-	cond *sync.Cond
+	// retries is amount of times call was repeated because of rate limit
+	retries int
+
+	call func(ctx context.Context) (any, error)
+	// ctx is passed to call, it is cancelled when the last producer releases item
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed when item is invoked, Response mustn't be read before it
+	done chan struct{}
 }
 
-func (item *QueueItem) makeCall() {
-	value := reflect.ValueOf(item.Func)
-
-	args := make([]reflect.Value, 0)
-	for _, arg := range item.Args {
-		args = append(args, reflect.ValueOf(arg))
+// before tells if item has to be called earlier than other one, priorities are aged when aging is set
+func (item *QueueItem) before(other *QueueItem, aging time.Duration) bool {
+	if aging > 0 {
+		due, otherDue := item.due(aging), other.due(aging)
+		if !due.Equal(otherDue) {
+			return due.Before(otherDue)
+		}
+	} else if item.Priority != other.Priority {
+		return item.Priority > other.Priority
 	}
 
-	result := value.Call(args)
-
-	if len(result) == 1 {
-		item.Response.Error = checkIfError(result[0].Interface())
-	} else {
-		item.Response.Value = result[0].Interface()
-		item.Response.Error = checkIfError(result[1].Interface())
-	}
-	item.invoked = INVOKED
+	return item.sequence < other.sequence
 }
 
-func checkIfError(respErr interface{}) error {
-	if err, ok := respErr.(error); ok {
-		return err
+func (item *QueueItem) lane() lane {
+	return lane{
+		resource: item.Resource,
+		priority: item.Priority,
 	}
-	return nil
 }
 
-func (item *QueueItem) waitInvoked() {
-	item.mu.Lock()
-	defer item.mu.Unlock()
-
-	if item.cond == nil {
-		item.cond = sync.NewCond(&item.mu)
-	}
-
-	for item.invoked != INVOKED {
-		item.cond.Wait()
+func (item *QueueItem) waitInvoked(ctx context.Context) error {
+	select {
+	case <-item.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func (item *QueueItem) callFunction() {
-	item.mu.Lock()
-	defer item.mu.Unlock()
+// callFunction invokes item, cooldown to wait before repeating rate limited call is returned
+// with flag that the limit is credential wide, such item has to be queued again
+func (item *QueueItem) callFunction(backoff Backoff) (time.Duration, bool, bool) {
+	item.Response.Value, item.Response.Error = item.call(item.ctx)
+
+	if cooldown, ok := cooldownOf(item.Response.Error); ok && item.retries < backoff.MaxRetries {
+		delay := backoff.delay(item.retries, cooldown)
+		wide := isCredentialWide(item.Response.Error)
+		item.retries++
+		item.Response = Response{}
+		return delay, wide, true
+	}
 
-	item.makeCall()
+	item.invoked = INVOKED
+	close(item.done)
 
-	if item.cond != nil {
-		item.cond.Broadcast()
-	}
+	return 0, false, false
 }
@@ -0,0 +1,67 @@
+package pqueue
+
+import (
+	"fmt"
+	"time"
+)
+
+// shareWindow is amount of the latest calls over which guaranteed shares of priority classes are kept
+const shareWindow = 100
+
+// lane keeps pending items of one priority class that spend budget of one rate limit resource
+type lane struct {
+	resource string
+	priority int
+}
+
+// ClassName returns name of priority class, it is used to report class metrics
+func ClassName(priority int) string {
+	switch priority {
+	case HighPriority:
+		return "high"
+	case NormalPriority:
+		return "normal"
+	case LowPriority:
+		return "low"
+	default:
+		return fmt.Sprintf("priority_%d", priority)
+	}
+}
+
+// due returns the time item would be enqueued at if its priority came only from waiting,
+// waiting for aging interval raises priority by one, so earlier due item is called first
+func (item *QueueItem) due(aging time.Duration) time.Time {
+	return item.EnqueuedAt.Add(-time.Duration(item.Priority) * aging)
+}
+
+// dispatchHistory remembers priority classes of the latest calls,
+// so class that got less than its guaranteed share is served before the others
+type dispatchHistory struct {
+	priorities []int
+	next       int
+	counts     map[int]int
+}
+
+func newDispatchHistory() *dispatchHistory {
+	return &dispatchHistory{
+		priorities: make([]int, 0, shareWindow),
+		counts:     make(map[int]int),
+	}
+}
+
+func (h *dispatchHistory) record(priority int) {
+	if len(h.priorities) < shareWindow {
+		h.priorities = append(h.priorities, priority)
+	} else {
+		h.counts[h.priorities[h.next]]--
+		h.priorities[h.next] = priority
+		h.next = (h.next + 1) % shareWindow
+	}
+
+	h.counts[priority]++
+}
+
+// deficit returns how many calls class lacks to get its share, class isn't starving when it is not positive
+func (h *dispatchHistory) deficit(priority int, share float64) float64 {
+	return share*float64(len(h.priorities)) - float64(h.counts[priority])
+}
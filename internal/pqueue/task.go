@@ -0,0 +1,83 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Key identifies queued call, calls with equal keys are made once and share the result
+type Key struct {
+	Method string
+	Args   []string
+	// Resources are rate limit resources which budgets call spends, call spends core one when none is declared
+	Resources []string
+}
+
+func NewKey(method string, args ...string) Key {
+	return Key{
+		Method: method,
+		Args:   args,
+	}
+}
+
+// Spending declares rate limit resources call spends, the first one is the main one,
+// call waits until buckets of all of them allow it
+func (k Key) Spending(resources ...string) Key {
+	k.Resources = resources
+	return k
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s(%s)", k.Method, strings.Join(k.Args, ", "))
+}
+
+// Submit queues call and waits for its result until ctx is done, calls submitted with the same key share one result,
+// call that nobody waits for anymore is dropped from queue
+func Submit[T any](ctx context.Context, queue *PriorityQueue, key Key, call func(ctx context.Context) (T, error), priority int) (T, error) {
+	var result T
+
+	item := queue.Add(&QueueItem{
+		Id:        key.String(),
+		Method:    key.Method,
+		Args:      key.Args,
+		Resources: key.Resources,
+		Priority:  priority,
+		call: func(ctx context.Context) (any, error) {
+			return call(ctx)
+		},
+	})
+
+	//item is released by pointer, it could be cancelled and replaced by another one with the same key
+	err := item.waitInvoked(ctx)
+	queue.Release(item)
+	if err != nil {
+		return result, errors.Wrap(err, fmt.Sprintf("failed to wait until `%s` is invoked", item.Id))
+	}
+
+	if item.Response.Error != nil {
+		return result, item.Response.Error
+	}
+
+	//call of another submitter with the same key could return different type
+	if item.Response.Value == nil {
+		return result, nil
+	}
+	result, ok := item.Response.Value.(T)
+	if !ok {
+		return result, errors.Errorf("call `%s` returned `%T` instead of `%T`", item.Id, item.Response.Value, result)
+	}
+
+	return result, nil
+}
+
+// Exec queues call that returns only error and waits for it
+func Exec(ctx context.Context, queue *PriorityQueue, key Key, call func(ctx context.Context) error, priority int) error {
+	_, err := Submit(ctx, queue, key, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, call(ctx)
+	}, priority)
+
+	return err
+}
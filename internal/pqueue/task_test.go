@@ -0,0 +1,219 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitSharesCallOfEqualKeys(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	const producers = 50
+
+	var calls int64
+	key := NewKey("GetUserFromApi", "username")
+
+	var wg sync.WaitGroup
+	results := make([]string, producers)
+	errs := make([]error, producers)
+
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			results[i], errs[i] = Submit(context.Background(), queue, key, func(context.Context) (string, error) {
+				atomic.AddInt64(&calls, 1)
+				return "user", nil
+			}, NormalPriority)
+		}(i)
+	}
+
+	waitFor(t, func() bool {
+		items := queue.Items()
+		return len(items) == 1 && items[0].Amount == producers
+	})
+
+	queue.Resume()
+	wg.Wait()
+
+	if atomic.LoadInt64(&calls) != 1 {
+		t.Fatalf("expected one call, got %d", calls)
+	}
+	for i := 0; i < producers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %s", errs[i])
+		}
+		if results[i] != "user" {
+			t.Fatalf("expected shared result, got `%s`", results[i])
+		}
+	}
+}
+
+func TestExecCallsEveryKeyOnce(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+
+	const keys, producersPerKey = 20, 5
+
+	calls := make([]int64, keys)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, keys*producersPerKey)
+
+	for i := 0; i < keys; i++ {
+		for j := 0; j < producersPerKey; j++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				errs <- Exec(context.Background(), queue, NewKey("RemoveUserFromApi", fmt.Sprint(i)), func(context.Context) error {
+					atomic.AddInt64(&calls[i], 1)
+					return nil
+				}, LowPriority)
+			}(i)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	//producers that came after the call was made queue it again, but never at the same time
+	for i := range calls {
+		if calls[i] < 1 || calls[i] > producersPerKey {
+			t.Fatalf("unexpected amount of calls %d for key %d", calls[i], i)
+		}
+	}
+
+	if queue.Len() != 0 {
+		t.Fatalf("expected empty queue, got %d items", queue.Len())
+	}
+}
+
+func TestSubmitDropsCallNobodyWaitsFor(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	var calls int64
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := Submit(ctx, queue, NewKey("FindType", "org"), func(context.Context) (string, error) {
+			atomic.AddInt64(&calls, 1)
+			return "", nil
+		}, NormalPriority)
+		result <- err
+	}()
+
+	waitFor(t, func() bool { return queue.Len() == 1 })
+	cancel()
+
+	if err := <-result; err == nil {
+		t.Fatal("expected error of cancelled producer")
+	}
+
+	waitFor(t, func() bool { return queue.Len() == 0 })
+	queue.Resume()
+	time.Sleep(2 * idleInterval)
+
+	if atomic.LoadInt64(&calls) != 0 {
+		t.Fatal("dropped item was called")
+	}
+}
+
+func TestSubmitReportsUnexpectedType(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+
+	_, err := Submit(context.Background(), queue, NewKey("FindType", "org"), func(context.Context) (any, error) {
+		return 1, nil
+	}, NormalPriority)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	queue.Pause()
+	key := NewKey("FindRepositoryOwner", "org/repo")
+
+	first := submitAsync(t, queue, key, NormalPriority, func(context.Context) (string, error) {
+		return "org", nil
+	})
+
+	var second error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, second = Submit(context.Background(), queue, key, func(context.Context) (int, error) {
+			return 0, nil
+		}, NormalPriority)
+	}()
+
+	waitFor(t, func() bool {
+		items := queue.Items()
+		return len(items) == 1 && items[0].Amount == 2
+	})
+	queue.Resume()
+
+	if err = <-first; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-done
+	if second == nil {
+		t.Fatal("expected error of producer that waits for another type")
+	}
+}
+
+func TestSubmitRaisesPriorityOfQueuedCall(t *testing.T) {
+	queue := newTestQueue(t, RateLimit{})
+	queue.Pause()
+
+	log := &callLog{}
+	key := NewKey("GetUserFromApi", "username")
+
+	worker := submitAsync(t, queue, key, LowPriority, log.call("user"))
+	normal := submitAsync(t, queue, NewKey("normal"), NormalPriority, log.call("normal"))
+
+	//interactive caller joins call queued by worker
+	interactive := make(chan error, 1)
+	go func() {
+		_, err := Submit(context.Background(), queue, key, log.call("user"), HighPriority)
+		interactive <- err
+	}()
+
+	waitFor(t, func() bool {
+		for _, item := range queue.Items() {
+			if item.Amount == 2 {
+				return true
+			}
+		}
+		return false
+	})
+
+	items := queue.Items()
+	if items[0].Method != "GetUserFromApi" || items[0].Priority != HighPriority {
+		t.Fatalf("expected shared item to be raised to high priority, got %+v", items)
+	}
+
+	queue.Resume()
+
+	for _, result := range []<-chan error{worker, normal, interactive} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := []string{"user", "normal"}
+	if calls := log.get(); fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+}
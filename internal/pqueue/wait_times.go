@@ -0,0 +1,83 @@
+package pqueue
+
+import (
+	"sort"
+	"time"
+)
+
+// WaitTimeBounds are upper bounds of wait time histogram buckets, the last bucket counts the rest
+var WaitTimeBounds = []time.Duration{
+	time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+}
+
+// WaitTimes is histogram of time that calls of priority class waited in queue
+type WaitTimes struct {
+	Priority int
+	// Buckets[i] counts calls that waited longer than WaitTimeBounds[i-1] but not longer than WaitTimeBounds[i]
+	Buckets []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+func newWaitTimes(priority int) *WaitTimes {
+	return &WaitTimes{
+		Priority: priority,
+		Buckets:  make([]uint64, len(WaitTimeBounds)+1),
+	}
+}
+
+func (w *WaitTimes) observe(wait time.Duration) {
+	bucket := sort.Search(len(WaitTimeBounds), func(i int) bool {
+		return wait <= WaitTimeBounds[i]
+	})
+
+	w.Buckets[bucket]++
+	w.Count++
+	w.Sum += wait
+}
+
+func (w *WaitTimes) merge(other WaitTimes) {
+	for i, count := range other.Buckets {
+		w.Buckets[i] += count
+	}
+
+	w.Count += other.Count
+	w.Sum += other.Sum
+}
+
+func (w *WaitTimes) copy() WaitTimes {
+	result := *w
+	result.Buckets = append([]uint64(nil), w.Buckets...)
+
+	return result
+}
+
+// mergeWaitTimes sums histograms of the same classes, the most prioritized class goes first
+func mergeWaitTimes(histograms ...[]WaitTimes) []WaitTimes {
+	merged := make(map[int]*WaitTimes)
+	for _, waitTimes := range histograms {
+		for _, classWaitTimes := range waitTimes {
+			if _, ok := merged[classWaitTimes.Priority]; !ok {
+				merged[classWaitTimes.Priority] = newWaitTimes(classWaitTimes.Priority)
+			}
+			merged[classWaitTimes.Priority].merge(classWaitTimes)
+		}
+	}
+
+	result := make([]WaitTimes, 0, len(merged))
+	for _, classWaitTimes := range merged {
+		result = append(result, *classWaitTimes)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Priority > result[j].Priority
+	})
+
+	return result
+}
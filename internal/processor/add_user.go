@@ -1,12 +1,14 @@
 package processor
 
 import (
+	"context"
+	"fmt"
+	"net/mail"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -15,13 +17,14 @@ import (
 func (p *processor) validateAddUser(msg data.ModulePayload) error {
 	return validation.Errors{
 		"link":         validation.Validate(msg.Link, validation.Required),
-		"username":     validation.Validate(msg.Username, validation.Required),
+		"username":     validation.Validate(msg.Username, validation.When(msg.Email == "", validation.Required)),
+		"email":        validation.Validate(msg.Email, validation.By(isEmail)),
 		"user_id":      validation.Validate(msg.UserId, validation.Required),
 		"access_level": validation.Validate(msg.AccessLevel, validation.Required),
 	}.Filter()
 }
 
-func (p *processor) HandleAddUserAction(msg data.ModulePayload) error {
+func (p *processor) HandleAddUserAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateAddUser(msg)
@@ -30,13 +33,26 @@ func (p *processor) HandleAddUserAction(msg data.ModulePayload) error {
 		return errors.Wrap(err, "failed to validate fields")
 	}
 	msg.Link = strings.ToLower(msg.Link)
+	msg.AccessLevel = data.NormalizeRole(msg.AccessLevel)
 	userId, err := strconv.ParseInt(msg.UserId, 10, 64)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to parse user id `%s` for message action with id `%s`", msg.UserId, msg.RequestId)
 		return errors.Wrap(err, "failed to parse user id")
 	}
 
-	permission, err := p.addUser(msg.Link, msg.Username, msg.AccessLevel)
+	//new hires may not know their github username yet
+	if msg.Email != "" {
+		err = p.inviteByEmail(ctx, msg, userId)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to invite user by email for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to invite user by email")
+		}
+
+		p.log.Infof("finish handle message action with id `%s`, user was invited by email", msg.RequestId)
+		return nil
+	}
+
+	permission, err := p.addUser(ctx, msg.Link, msg.Username, msg.AccessLevel)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to add user from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while adding user from api")
@@ -46,6 +62,17 @@ func (p *processor) HandleAddUserAction(msg data.ModulePayload) error {
 	permission.RequestId = msg.RequestId
 	permission.CreatedAt = time.Now()
 
+	if permission.Invitation != nil {
+		err = p.storeInvitation(*permission)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to store invitation for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to store invitation")
+		}
+
+		p.log.Infof("finish handle message action with id `%s`, user was invited", msg.RequestId)
+		return nil
+	}
+
 	user := data.User{
 		Id:        &userId,
 		Username:  permission.Username,
@@ -94,25 +121,39 @@ func (p *processor) HandleAddUserAction(msg data.ModulePayload) error {
 	return nil
 }
 
-func (p *processor) addUser(link, username, accessLevel string) (*data.Permission, error) {
-	typeTo, err := p.getLinkType(link, pqueue.NormalPriority)
+func (p *processor) addUser(ctx context.Context, link, username, accessLevel string) (*data.Permission, error) {
+	typeTo, err := p.getLinkType(ctx, link, pqueue.NormalPriority)
 	if err != nil {
 		return nil, errors.Wrap(err, "some error while getting link type api")
 	}
 
-	isHere, err := p.isUserInSubmodule(link, username, typeTo)
+	if typeTo == data.Repository {
+		if err = p.checkRepositoryRole(ctx, link, accessLevel); err != nil {
+			return nil, errors.Wrap(err, "failed to check repository role")
+		}
+	}
+
+	current, err := p.getUserInSubmodule(ctx, link, username, typeTo)
 	if err != nil {
 		return nil, errors.Wrap(err, "some error while checking user for link")
 	}
 
-	if isHere {
-		return nil, errors.New("user is already in submodule")
+	//resumed job finds user added by its own request made before restart
+	if current != nil {
+		if data.NormalizeRole(current.AccessLevel) != accessLevel {
+			return nil, errors.New(fmt.Sprintf("user is already in submodule with `%s` access level", current.AccessLevel))
+		}
+
+		return current, nil
 	}
 
-	permission, err := github.GetPermission(
-		p.pqueues.SuperUserPQueue,
-		any(p.githubClient.AddUserFromApi),
-		[]any{any(typeTo), any(link), any(username), any(accessLevel)},
+	permission, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(link).SuperUserPQueue,
+		pqueue.NewKey("AddUserFromApi", typeTo, link, username, accessLevel),
+		func(ctx context.Context) (*data.Permission, error) {
+			return p.githubClient.AddUserFromApi(ctx, typeTo, link, username, accessLevel)
+		},
 		pqueue.NormalPriority,
 	)
 	if err != nil {
@@ -125,3 +166,79 @@ func (p *processor) addUser(link, username, accessLevel string) (*data.Permissio
 
 	return permission, nil
 }
+
+// storeInvitation keeps invitation apart from permissions, user gets permission when worker sees it accepted
+func (p *processor) storeInvitation(permission data.Permission) error {
+	invitation := *permission.Invitation
+	invitation.RequestId = permission.RequestId
+	invitation.UserId = permission.UserId
+	invitation.Username = permission.Username
+	invitation.GithubId = &permission.GithubId
+	invitation.Link = permission.Link
+	invitation.Type = permission.Type
+	invitation.AccessLevel = permission.AccessLevel
+	invitation.CreatedAt = permission.CreatedAt
+
+	return p.invitationsQ.Upsert(invitation)
+}
+
+func (p *processor) inviteByEmail(ctx context.Context, msg data.ModulePayload, userId int64) error {
+	typeTo, err := p.getLinkType(ctx, msg.Link, pqueue.NormalPriority)
+	if err != nil {
+		return errors.Wrap(err, "some error while getting link type api")
+	}
+
+	if typeTo != data.Organization {
+		return errors.New("only organization invitations can be sent by email")
+	}
+
+	//invitations gone from GitHub in unknown state don't block new ones
+	pending, err := p.invitationsQ.
+		FilterByLinks(msg.Link).
+		FilterByEmails(msg.Email).
+		FilterByStates(data.InvitationPending).
+		Get()
+	if err != nil {
+		return errors.Wrap(err, "failed to get invitation from db")
+	}
+
+	if pending != nil {
+		return errors.New("email is already invited to organization")
+	}
+
+	invitation, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(msg.Link).SuperUserPQueue,
+		pqueue.NewKey("InviteToOrganizationByEmailFromApi", msg.Link, msg.Email, msg.AccessLevel),
+		func(ctx context.Context) (*data.Invitation, error) {
+			return p.githubClient.InviteToOrganizationByEmailFromApi(ctx, msg.Link, msg.Email, msg.AccessLevel)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, "some error while inviting user from api")
+	}
+
+	if invitation == nil {
+		return errors.New("something wrong with inviting user")
+	}
+
+	invitation.UserId = &userId
+	invitation.RequestId = msg.RequestId
+	invitation.CreatedAt = time.Now()
+
+	return p.invitationsQ.Upsert(*invitation)
+}
+
+func isEmail(value interface{}) error {
+	email, _ := value.(string)
+	if email == "" {
+		return nil
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return errors.New("must be a valid email address")
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/github"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+)
+
+// organizationClient keeps members of one organization, the rest of client methods aren't expected to be called
+type organizationClient struct {
+	github.GithubClient
+
+	mu      sync.Mutex
+	members map[string]string
+	puts    int
+}
+
+func (c *organizationClient) FindType(context.Context, string) (*github.TypeSub, error) {
+	return &github.TypeSub{Type: data.Organization}, nil
+}
+
+func (c *organizationClient) CheckUserFromApi(_ context.Context, link, username, _ string) (*data.Permission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	accessLevel, ok := c.members[username]
+	if !ok {
+		return nil, nil
+	}
+
+	return &data.Permission{
+		Link:        link,
+		Type:        data.Organization,
+		Username:    username,
+		AccessLevel: accessLevel,
+	}, nil
+}
+
+func (c *organizationClient) AddUserFromApi(_ context.Context, _, link, username, permission string) (*data.Permission, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.puts++
+	c.members[username] = permission
+
+	return &data.Permission{
+		Link:        link,
+		Type:        data.Organization,
+		Username:    username,
+		AccessLevel: permission,
+	}, nil
+}
+
+func newTestProcessor(t *testing.T, client github.GithubClient) *processor {
+	t.Helper()
+
+	pqueues := pqueue.NewPQueues(pqueue.RateLimit{}, nil)
+
+	stop := make(chan struct{})
+	pqueues.ProcessQueues(stop)
+	t.Cleanup(func() { close(stop) })
+
+	return &processor{
+		githubClient: client,
+		pqueues:      &pqueues,
+	}
+}
+
+func TestAddUserReplayedAfterPut(t *testing.T) {
+	client := &organizationClient{members: make(map[string]string)}
+	p := newTestProcessor(t, client)
+
+	first, err := p.addUser(context.Background(), "org", "username", "member")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	//job is handled again when service restarted before it was finished
+	replayed, err := p.addUser(context.Background(), "org", "username", "member")
+	if err != nil {
+		t.Fatalf("expected replayed job to succeed, got error: %s", err)
+	}
+
+	if client.puts != 1 {
+		t.Fatalf("expected one PUT, got %d", client.puts)
+	}
+	if replayed.AccessLevel != first.AccessLevel || replayed.Username != first.Username {
+		t.Fatalf("expected replayed job to return added permission %+v, got %+v", first, replayed)
+	}
+}
+
+func TestAddUserWithAnotherAccessLevel(t *testing.T) {
+	client := &organizationClient{members: map[string]string{"username": "admin"}}
+	p := newTestProcessor(t, client)
+
+	if _, err := p.addUser(context.Background(), "org", "username", "member"); err == nil {
+		t.Fatal("expected error for user that is already in submodule with another access level")
+	}
+	if client.puts != 0 {
+		t.Fatalf("expected no PUT, got %d", client.puts)
+	}
+}
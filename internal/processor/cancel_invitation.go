@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (p *processor) validateCancelInvitation(msg data.ModulePayload) error {
+	return validation.Errors{
+		"link":     validation.Validate(msg.Link, validation.Required),
+		"username": validation.Validate(msg.Username, validation.When(msg.Email == "", validation.Required)),
+		"email":    validation.Validate(msg.Email, validation.By(isEmail)),
+	}.Filter()
+}
+
+func (p *processor) HandleCancelInvitationAction(ctx context.Context, msg data.ModulePayload) error {
+	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
+
+	err := p.validateCancelInvitation(msg)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to validate fields for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to validate fields")
+	}
+
+	msg.Link = strings.ToLower(msg.Link)
+	invitationsQ := p.invitationsQ.FilterByLinks(msg.Link).FilterByStates(data.InvitationPending, data.InvitationExpired)
+	if msg.Email != "" {
+		invitationsQ = invitationsQ.FilterByEmails(msg.Email)
+	} else {
+		invitationsQ = invitationsQ.FilterByUsernames(msg.Username)
+	}
+
+	invitation, err := invitationsQ.Get()
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to get invitation from db for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to get invitation from db")
+	}
+
+	if invitation == nil {
+		p.log.Errorf("no invitation for user in link for message action with id `%s`", msg.RequestId)
+		return errors.New("no invitation for user in link")
+	}
+
+	var invitationId int64
+	if invitation.InvitationId != nil {
+		invitationId = *invitation.InvitationId
+	}
+
+	err = pqueue.Exec(
+		ctx,
+		p.pqueues.ForLink(msg.Link).SuperUserPQueue,
+		pqueue.NewKey("CancelInvitationFromApi", invitation.Link, invitation.Username, invitation.Type, strconv.FormatInt(invitationId, 10)),
+		func(ctx context.Context) error {
+			return p.githubClient.CancelInvitationFromApi(ctx, invitation.Link, invitation.Username, invitation.Type, invitationId)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to cancel invitation from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while cancelling invitation from api")
+	}
+
+	cancelled := data.InvitationCancelled
+	err = p.invitationsQ.FilterByIds(invitation.Id).Update(data.InvitationToUpdate{
+		State: &cancelled,
+	})
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to update invitation state for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to update invitation state")
+	}
+
+	p.log.Infof("finish handle message action with id `%s`", msg.RequestId)
+	return nil
+}
@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (p *processor) validateConvertToOutside(msg data.ModulePayload) error {
+	return validation.Errors{
+		"link":     validation.Validate(msg.Link, validation.Required),
+		"username": validation.Validate(msg.Username, validation.Required),
+	}.Filter()
+}
+
+func (p *processor) HandleConvertToOutsideAction(ctx context.Context, msg data.ModulePayload) error {
+	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
+
+	err := p.validateConvertToOutside(msg)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to validate fields for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to validate fields")
+	}
+
+	msg.Link = strings.ToLower(msg.Link)
+	msg.Type, err = p.getLinkType(ctx, msg.Link, pqueue.NormalPriority)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to get link type from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while getting link type api")
+	}
+
+	if msg.Type != data.Organization {
+		p.log.Errorf("link is not an organization for message action with id `%s`", msg.RequestId)
+		return errors.New("only organization members can be converted to outside collaborators")
+	}
+
+	userApi, err := pqueue.Submit(
+		ctx,
+		p.pqueues.UserPQueue,
+		pqueue.NewKey("GetUserFromApi", msg.Username),
+		func(ctx context.Context) (*data.User, error) {
+			return p.githubClient.GetUserFromApi(ctx, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to get user from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while getting user from api")
+	}
+
+	if userApi == nil {
+		p.log.Errorf("something wrong with user for message action with id `%s`", msg.RequestId)
+		return errors.Errorf("something wrong with user from api")
+	}
+
+	//repositories user had own access level to, others were inherited from organization
+	repoPermissions, err := p.permissionsQ.
+		FilterByGithubIds(userApi.GithubId).
+		FilterByParentLinks(msg.Link).
+		FilterByTypes(data.Repository).
+		Select()
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to select repository permissions for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to select repository permissions")
+	}
+
+	err = pqueue.Exec(
+		ctx,
+		p.pqueues.ForLink(msg.Link).SuperUserPQueue,
+		pqueue.NewKey("ConvertMemberToOutsideCollaboratorFromApi", msg.Link, msg.Username),
+		func(ctx context.Context) error {
+			return p.githubClient.ConvertMemberToOutsideCollaboratorFromApi(ctx, msg.Link, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to convert user from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while converting user from api")
+	}
+
+	kept := make([]data.Permission, 0)
+	for _, repoPermission := range repoPermissions {
+		permission, err := pqueue.Submit(
+			ctx,
+			p.pqueues.ForLink(repoPermission.Link).UserPQueue,
+			pqueue.NewKey("CheckRepositoryCollaborator", repoPermission.Link, msg.Username),
+			func(ctx context.Context) (*data.Permission, error) {
+				return p.githubClient.CheckRepositoryCollaborator(ctx, repoPermission.Link, msg.Username)
+			},
+			pqueue.NormalPriority,
+		)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to check collaborator from API for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "some error while checking collaborator from api")
+		}
+
+		if permission == nil {
+			continue
+		}
+
+		kept = append(kept, data.Permission{
+			RequestId:        msg.RequestId,
+			UserId:           repoPermission.UserId,
+			Username:         repoPermission.Username,
+			GithubId:         repoPermission.GithubId,
+			AccessLevel:      permission.AccessLevel,
+			Link:             repoPermission.Link,
+			Type:             data.Repository,
+			CreatedAt:        time.Now(),
+			CollaboratorKind: data.CollaboratorOutside,
+		})
+	}
+
+	err = p.managerQ.Transaction(func() error {
+		err = p.deleteLowerLevelPermissions(userApi.GithubId, msg.Link, msg.Type)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to delete permission from db for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to delete permission")
+		}
+
+		for _, permission := range kept {
+			if err = p.permissionsQ.Upsert(permission); err != nil {
+				p.log.WithError(err).Errorf("failed to upsert permission in permission db for message action with id `%s`", msg.RequestId)
+				return errors.Wrap(err, "failed to upsert permission in permission db")
+			}
+
+			err = p.indexHasParentChild(permission.GithubId, permission.Link)
+			if err != nil {
+				p.log.WithError(err).Errorf("failed to check has parent/child for message action with id `%s`", msg.RequestId)
+				return errors.Wrap(err, "failed to check parent level")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to make convert user transaction for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to make convert user transaction")
+	}
+
+	p.log.Infof("finish handle message action with id `%s`", msg.RequestId)
+	return nil
+}
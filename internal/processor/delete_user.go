@@ -1,8 +1,8 @@
 package processor
 
 import (
+	"context"
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -14,7 +14,7 @@ func (p *processor) validateDeleteUser(msg data.ModulePayload) error {
 	}.Filter()
 }
 
-func (p *processor) HandleDeleteUserAction(msg data.ModulePayload) error {
+func (p *processor) HandleDeleteUserAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateDeleteUser(msg)
@@ -23,7 +23,15 @@ func (p *processor) HandleDeleteUserAction(msg data.ModulePayload) error {
 		return errors.Wrap(err, "failed to validate fields")
 	}
 
-	userApi, err := github.GetUser(p.pqueues.UserPQueue, any(p.githubClient.GetUserFromApi), []any{any(msg.Username)}, pqueue.NormalPriority)
+	userApi, err := pqueue.Submit(
+		ctx,
+		p.pqueues.UserPQueue,
+		pqueue.NewKey("GetUserFromApi", msg.Username),
+		func(ctx context.Context) (*data.User, error) {
+			return p.githubClient.GetUserFromApi(ctx, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get user from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting user from api")
@@ -41,7 +49,7 @@ func (p *processor) HandleDeleteUserAction(msg data.ModulePayload) error {
 	}
 
 	for _, permission := range permissions {
-		err = p.removePermissionFromRemoteAndLocal(permission)
+		err = p.removePermissionFromRemoteAndLocal(ctx, permission)
 		if err != nil {
 			p.log.WithError(err).Errorf("failed to remove permission from remote and local for message action with id `%s`", msg.RequestId)
 			return errors.Wrap(err, "failed to remove permission from remote and local")
@@ -58,18 +66,22 @@ func (p *processor) HandleDeleteUserAction(msg data.ModulePayload) error {
 	return nil
 }
 
-func (p *processor) removePermissionFromRemoteAndLocal(permission data.Permission) error {
-	isHere, err := p.isUserInSubmodule(permission.Link, permission.Username, permission.Type)
+func (p *processor) removePermissionFromRemoteAndLocal(ctx context.Context, permission data.Permission) error {
+	isHere, err := p.isUserInSubmodule(ctx, permission.Link, permission.Username, permission.Type)
 	if err != nil {
 		return errors.Wrap(err, "some error while checking user from api")
 	}
 
 	if isHere {
-		err = github.GetRequestError(
-			p.pqueues.SuperUserPQueue,
-			any(p.githubClient.RemoveUserFromApi),
-			[]any{any(permission.Link), any(permission.Username), any(permission.Type)},
-			pqueue.NormalPriority)
+		err = pqueue.Exec(
+			ctx,
+			p.pqueues.ForLink(permission.Link).SuperUserPQueue,
+			pqueue.NewKey("RemoveUserFromApi", permission.Link, permission.Username, permission.Type),
+			func(ctx context.Context) error {
+				return p.githubClient.RemoveUserFromApi(ctx, permission.Link, permission.Username, permission.Type)
+			},
+			pqueue.NormalPriority,
+		)
 		if err != nil {
 			return errors.Wrap(err, "some error while removing user from api")
 		}
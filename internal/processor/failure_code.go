@@ -0,0 +1,35 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/acs-dl/github-module-svc/internal/helpers"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// FailureInvalidRequest is failure code of messages that didn't pass validation
+	FailureInvalidRequest = "invalid_request"
+	// FailureDeadlineExceeded is failure code of messages that weren't handled before their deadline
+	FailureDeadlineExceeded = "deadline_exceeded"
+	// FailureCancelled is failure code of messages which queued call was cancelled by operator
+	FailureCancelled = "cancelled"
+)
+
+// FailureCode tells orchestrator why handling of message failed,
+// errors returned by GitHub keep their code through wrapping
+func FailureCode(err error) string {
+	if _, ok := errors.Cause(err).(validation.Errors); ok {
+		return FailureInvalidRequest
+	}
+	if errors.Cause(err) == context.DeadlineExceeded {
+		return FailureDeadlineExceeded
+	}
+	if errors.Cause(err) == pqueue.ErrCancelled {
+		return FailureCancelled
+	}
+
+	return string(helpers.ErrorCodeOf(err))
+}
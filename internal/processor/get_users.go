@@ -1,10 +1,13 @@
 package processor
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -16,7 +19,7 @@ func (p *processor) validateGetUsers(msg data.ModulePayload) error {
 	}.Filter()
 }
 
-func (p *processor) HandleGetUsersAction(msg data.ModulePayload) error {
+func (p *processor) HandleGetUsersAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateGetUsers(msg)
@@ -25,37 +28,52 @@ func (p *processor) HandleGetUsersAction(msg data.ModulePayload) error {
 		return errors.Wrap(err, "failed to validate fields")
 	}
 
-	msg.Type, err = p.getLinkType(msg.Link, pqueue.LowPriority)
+	msg.Type, err = p.getLinkType(ctx, msg.Link, pqueue.LowPriority)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get link type from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting link type api")
 	}
 
-	permissions, err := github.GetPermissions(p.pqueues.SuperUserPQueue, any(p.githubClient.GetUsersFromApi), []any{any(msg.Link), any(msg.Type)}, pqueue.LowPriority)
+	permissions, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(msg.Link).UserPQueue,
+		//members are fetched with GraphQL, REST API is a fallback
+		pqueue.NewKey("GetUsersFromApi", msg.Link, msg.Type).Spending(data.GraphqlRateLimit, data.CoreRateLimit),
+		func(ctx context.Context) ([]data.Permission, error) {
+			return p.githubClient.GetUsersFromApi(ctx, msg.Link, msg.Type)
+		},
+		pqueue.LowPriority,
+	)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get users from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting users from api")
 	}
 
+	if msg.Type == data.Organization {
+		permissions, err = p.applyOrganizationRoles(ctx, msg.Link, permissions)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to apply organization roles for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to apply organization roles")
+		}
+
+		billingManagers, err := p.getBillingManagers(ctx, msg.Link, permissions)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to get billing managers for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to get billing managers")
+		}
+
+		permissions = append(permissions, billingManagers...)
+	}
+
 	usersToUnverified := make([]data.User, 0)
 
 	for _, permission := range permissions {
-		//api doesn't return role for organization members
-		if msg.Type == data.Organization {
-			checkPermission, err := github.GetPermission(
-				p.pqueues.SuperUserPQueue,
-				any(p.githubClient.CheckOrganizationCollaborator), []any{any(msg.Link), any(permission.Username)},
-				pqueue.LowPriority)
+		if msg.Type == data.Repository && permission.CollaboratorKind == data.CollaboratorMember {
+			permission.CollaboratorKind, err = p.getMemberCollaboratorKind(msg.Link, permission.GithubId)
 			if err != nil {
-				p.log.WithError(err).Errorf("failed to get permission from api for message action with id `%s`", msg.RequestId)
-				return errors.Wrap(err, "failed to get permission from api")
+				p.log.WithError(err).Errorf("failed to get collaborator kind for message action with id `%s`", msg.RequestId)
+				return errors.Wrap(err, "failed to get collaborator kind")
 			}
-			if checkPermission == nil {
-				p.log.Errorf("user is not in organization for message action with id `%s`", msg.RequestId)
-				return errors.Errorf("user is not in organization")
-			}
-
-			permission.AccessLevel = checkPermission.AccessLevel
 		}
 
 		err = p.managerQ.Transaction(func() error {
@@ -116,6 +134,137 @@ func (p *processor) HandleGetUsersAction(msg data.ModulePayload) error {
 	return nil
 }
 
+// applyOrganizationRoles overrides membership roles of members with roles assigned apart from membership,
+// every roles request is queued on its own, because members listing doesn't tell about them
+func (p *processor) applyOrganizationRoles(ctx context.Context, link string, members []data.Permission) ([]data.Permission, error) {
+	roles, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("GetOrganizationRolesFromApi", link),
+		func(ctx context.Context) (map[string]int64, error) {
+			return p.githubClient.GetOrganizationRolesFromApi(ctx, link)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get organization roles")
+	}
+
+	indexes := make(map[int64]int)
+	for i, member := range members {
+		indexes[member.GithubId] = i
+	}
+
+	//roles with higher precedence go last to override others
+	for i := len(data.OrganizationRoles) - 1; i >= 0; i-- {
+		role := data.OrganizationRoles[i]
+
+		roleId, ok := roles[role]
+		if !ok {
+			continue
+		}
+
+		users, err := pqueue.Submit(
+			ctx,
+			p.pqueues.ForLink(link).UserPQueue,
+			pqueue.NewKey("GetOrganizationRoleUsersFromApi", link, strconv.FormatInt(roleId, 10)),
+			func(ctx context.Context) ([]data.Permission, error) {
+				return p.githubClient.GetOrganizationRoleUsersFromApi(ctx, link, roleId)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to get `%s` role users", role))
+		}
+
+		for _, user := range users {
+			index, ok := indexes[user.GithubId]
+			if !ok || members[index].AccessLevel == data.RoleAdmin {
+				continue
+			}
+
+			members[index].AccessLevel = role
+		}
+	}
+
+	return members, nil
+}
+
+// getBillingManagers checks known billing managers of organization, api doesn't list them,
+// so they are taken from stored permissions and accepted invitations
+func (p *processor) getBillingManagers(ctx context.Context, link string, members []data.Permission) ([]data.Permission, error) {
+	listed := make(map[string]bool)
+	for _, member := range members {
+		listed[strings.ToLower(member.Username)] = true
+	}
+
+	candidates := make([]string, 0)
+
+	permissions, err := p.permissionsQ.FilterByLinks(link).FilterByTypes(data.Organization).FilterByAccessLevels(data.BillingManager).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select billing manager permissions")
+	}
+	for _, permission := range permissions {
+		candidates = append(candidates, permission.Username)
+	}
+
+	invitations, err := p.invitationsQ.FilterByLinks(link).FilterByStates(data.InvitationAccepted).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select accepted invitations")
+	}
+	for _, invitation := range invitations {
+		if invitation.AccessLevel == data.BillingManager && invitation.Username != "" {
+			candidates = append(candidates, invitation.Username)
+		}
+	}
+
+	result := make([]data.Permission, 0)
+
+	for _, username := range candidates {
+		if listed[strings.ToLower(username)] {
+			continue
+		}
+		listed[strings.ToLower(username)] = true
+
+		membership, err := pqueue.Submit(
+			ctx,
+			p.pqueues.ForLink(link).UserPQueue,
+			pqueue.NewKey("CheckOrganizationCollaborator", link, username),
+			func(ctx context.Context) (*data.Permission, error) {
+				return p.githubClient.CheckOrganizationCollaborator(ctx, link, username)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to check membership of `%s`", username))
+		}
+
+		if membership == nil || membership.Invitation != nil || membership.AccessLevel != data.BillingManager {
+			continue
+		}
+
+		membership.CollaboratorKind = data.CollaboratorMember
+		result = append(result, *membership)
+	}
+
+	return result, nil
+}
+
+// getMemberCollaboratorKind tells apart members with access through team grants,
+// which worker indexes before organization repositories
+func (p *processor) getMemberCollaboratorKind(link string, githubId int64) (string, error) {
+	teamPermission, err := p.teamPermissionsQ.FilterByRepoLinks(link).FilterByGithubIds(githubId).Get()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get team permission")
+	}
+
+	if teamPermission != nil {
+		return data.CollaboratorTeam, nil
+	}
+
+	return data.CollaboratorMember, nil
+}
+
 func (p *processor) checkHasParent(permission data.Sub) error {
 	if permission.ParentId == nil {
 		hasParent := false
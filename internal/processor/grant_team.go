@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (p *processor) validateGrantTeam(msg data.ModulePayload) error {
+	return validation.Errors{
+		"link":         validation.Validate(msg.Link, validation.Required),
+		"team":         validation.Validate(msg.Team, validation.Required),
+		"access_level": validation.Validate(msg.AccessLevel, validation.Required),
+	}.Filter()
+}
+
+func (p *processor) HandleGrantTeamAction(ctx context.Context, msg data.ModulePayload) error {
+	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
+
+	err := p.validateGrantTeam(msg)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to validate fields for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to validate fields")
+	}
+
+	msg.Link = strings.ToLower(msg.Link)
+	msg.Team = strings.ToLower(msg.Team)
+
+	err = p.checkTeamAndRepository(ctx, msg.Team, msg.Link)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to check team and repository for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to check team and repository")
+	}
+
+	permission, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(msg.Team).SuperUserPQueue,
+		pqueue.NewKey("AddOrUpdateTeamInRepositoryFromApi", msg.Team, msg.Link, msg.AccessLevel),
+		func(ctx context.Context) (*data.TeamPermission, error) {
+			return p.githubClient.AddOrUpdateTeamInRepositoryFromApi(ctx, msg.Team, msg.Link, msg.AccessLevel)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to grant team from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while granting team from api")
+	}
+
+	err = p.teamPermissionsQ.Upsert(*permission)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to upsert team permission in db for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to upsert team permission in db")
+	}
+
+	p.log.Infof("finish handle message action with id `%s`", msg.RequestId)
+	return nil
+}
+
+// checkTeamAndRepository makes sure links point to team and repository of the same organization
+func (p *processor) checkTeamAndRepository(ctx context.Context, team, repository string) error {
+	teamType, err := p.getLinkType(ctx, team, pqueue.NormalPriority)
+	if err != nil {
+		return errors.Wrap(err, "failed to get team link type")
+	}
+	if teamType != data.Team {
+		return errors.Errorf("`%s` is not a team", team)
+	}
+
+	repositoryType, err := p.getLinkType(ctx, repository, pqueue.NormalPriority)
+	if err != nil {
+		return errors.Wrap(err, "failed to get repository link type")
+	}
+	if repositoryType != data.Repository {
+		return errors.Errorf("`%s` is not a repository", repository)
+	}
+
+	if strings.Split(team, "/")[0] != strings.Split(repository, "/")[0] {
+		return errors.Errorf("team `%s` and repository `%s` belong to different owners", team, repository)
+	}
+
+	return nil
+}
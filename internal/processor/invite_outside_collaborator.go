@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (p *processor) validateInviteOutsideCollaborator(msg data.ModulePayload) error {
+	return validation.Errors{
+		"link":     validation.Validate(msg.Link, validation.Required),
+		"username": validation.Validate(msg.Username, validation.Required),
+	}.Filter()
+}
+
+// HandleInviteOutsideCollaboratorAction invites outside collaborator into organization,
+// the rest is the same as adding user to organization
+func (p *processor) HandleInviteOutsideCollaboratorAction(ctx context.Context, msg data.ModulePayload) error {
+	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
+
+	err := p.validateInviteOutsideCollaborator(msg)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to validate fields for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to validate fields")
+	}
+
+	msg.Link = strings.ToLower(msg.Link)
+	collaborators, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(msg.Link).UserPQueue,
+		pqueue.NewKey("GetOutsideCollaboratorsFromApi", msg.Link),
+		func(ctx context.Context) ([]data.Permission, error) {
+			return p.githubClient.GetOutsideCollaboratorsFromApi(ctx, msg.Link)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to get outside collaborators from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while getting outside collaborators from api")
+	}
+
+	isOutside := false
+	for _, collaborator := range collaborators {
+		if strings.EqualFold(collaborator.Username, msg.Username) {
+			isOutside = true
+			break
+		}
+	}
+
+	if !isOutside {
+		p.log.Errorf("user is not an outside collaborator for message action with id `%s`", msg.RequestId)
+		return errors.New("user is not an outside collaborator")
+	}
+
+	if msg.AccessLevel == "" {
+		msg.AccessLevel = data.CollaboratorMember
+	}
+
+	return p.HandleAddUserAction(ctx, msg)
+}
@@ -22,38 +22,49 @@ const (
 )
 
 type Processor interface {
-	HandleGetUsersAction(msg data.ModulePayload) error
-	HandleAddUserAction(msg data.ModulePayload) error
-	HandleUpdateUserAction(msg data.ModulePayload) error
-	HandleRemoveUserAction(msg data.ModulePayload) error
-	HandleDeleteUserAction(msg data.ModulePayload) error
-	HandleVerifyUserAction(msg data.ModulePayload) error
+	HandleGetUsersAction(ctx context.Context, msg data.ModulePayload) error
+	HandleAddUserAction(ctx context.Context, msg data.ModulePayload) error
+	HandleUpdateUserAction(ctx context.Context, msg data.ModulePayload) error
+	HandleRemoveUserAction(ctx context.Context, msg data.ModulePayload) error
+	HandleDeleteUserAction(ctx context.Context, msg data.ModulePayload) error
+	HandleVerifyUserAction(ctx context.Context, msg data.ModulePayload) error
+	HandleGrantTeamAction(ctx context.Context, msg data.ModulePayload) error
+	HandleRevokeTeamAction(ctx context.Context, msg data.ModulePayload) error
+	HandleCancelInvitationAction(ctx context.Context, msg data.ModulePayload) error
+	HandleConvertToOutsideAction(ctx context.Context, msg data.ModulePayload) error
+	HandleInviteOutsideCollaboratorAction(ctx context.Context, msg data.ModulePayload) error
 	SendDeleteUser(uuid string, user data.User) error
 }
 
 type processor struct {
-	log             *logan.Entry
-	githubClient    github.GithubClient
-	permissionsQ    data.Permissions
-	subsQ           data.Subs
-	usersQ          data.Users
-	managerQ        *manager.Manager
-	sender          *sender.Sender
-	pqueues         *pqueue.PQueues
-	unverifiedTopic string
+	log              *logan.Entry
+	githubClient     github.GithubClient
+	permissionsQ     data.Permissions
+	teamPermissionsQ data.TeamPermissions
+	invitationsQ     data.Invitations
+	customRolesQ     data.CustomRoles
+	subsQ            data.Subs
+	usersQ           data.Users
+	managerQ         *manager.Manager
+	sender           *sender.Sender
+	pqueues          *pqueue.PQueues
+	unverifiedTopic  string
 }
 
 func NewProcessorAsInterface(cfg config.Config, ctx context.Context) interface{} {
 	return interface{}(&processor{
-		log:             cfg.Log().WithField("service", ServiceName),
-		githubClient:    github.GithubClientInstance(ctx),
-		sender:          sender.SenderInstance(ctx),
-		pqueues:         pqueue.PQueuesInstance(ctx),
-		managerQ:        manager.NewManager(cfg.DB()),
-		permissionsQ:    postgres.NewPermissionsQ(cfg.DB()),
-		subsQ:           postgres.NewSubsQ(cfg.DB()),
-		usersQ:          postgres.NewUsersQ(cfg.DB()),
-		unverifiedTopic: cfg.Amqp().Unverified,
+		log:              cfg.Log().WithField("service", ServiceName),
+		githubClient:     github.GithubClientInstance(ctx),
+		sender:           sender.SenderInstance(ctx),
+		pqueues:          pqueue.PQueuesInstance(ctx),
+		managerQ:         manager.NewManager(cfg.DB()),
+		permissionsQ:     postgres.NewPermissionsQ(cfg.DB()),
+		teamPermissionsQ: postgres.NewTeamPermissionsQ(cfg.DB()),
+		invitationsQ:     postgres.NewInvitationsQ(cfg.DB()),
+		customRolesQ:     postgres.NewCustomRolesQ(cfg.DB()),
+		subsQ:            postgres.NewSubsQ(cfg.DB()),
+		usersQ:           postgres.NewUsersQ(cfg.DB()),
+		unverifiedTopic:  cfg.Amqp().Unverified,
 	})
 }
 
@@ -1,10 +1,10 @@
 package processor
 
 import (
+	"context"
 	"strings"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -17,7 +17,7 @@ func (p *processor) validateRemoveUser(msg data.ModulePayload) error {
 	}.Filter()
 }
 
-func (p *processor) HandleRemoveUserAction(msg data.ModulePayload) error {
+func (p *processor) HandleRemoveUserAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateRemoveUser(msg)
@@ -38,7 +38,15 @@ func (p *processor) HandleRemoveUserAction(msg data.ModulePayload) error {
 		return errors.New("no user with such username")
 	}
 
-	userApi, err := github.GetUser(p.pqueues.UserPQueue, any(p.githubClient.GetUserFromApi), []any{any(msg.Username)}, pqueue.NormalPriority)
+	userApi, err := pqueue.Submit(
+		ctx,
+		p.pqueues.UserPQueue,
+		pqueue.NewKey("GetUserFromApi", msg.Username),
+		func(ctx context.Context) (*data.User, error) {
+			return p.githubClient.GetUserFromApi(ctx, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get user from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting user from api")
@@ -49,20 +57,38 @@ func (p *processor) HandleRemoveUserAction(msg data.ModulePayload) error {
 		return errors.Errorf("something wrong with user from api")
 	}
 
-	msg.Type, err = p.getLinkType(msg.Link, pqueue.NormalPriority)
+	msg.Type, err = p.getLinkType(ctx, msg.Link, pqueue.NormalPriority)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get link type from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting link type api")
 	}
 
-	err = github.GetRequestError(
-		p.pqueues.SuperUserPQueue,
-		any(p.githubClient.RemoveUserFromApi),
-		[]any{any(msg.Link), any(msg.Username), any(msg.Type)},
-		pqueue.NormalPriority)
-	if err != nil {
-		p.log.WithError(err).Errorf("failed to remove user from API for message action with id `%s`", msg.RequestId)
-		return errors.Wrap(err, "some error while removing user from api")
+	//organization role is revoked apart from membership
+	if msg.Type == data.Organization && data.IsOrganizationRole(msg.AccessLevel) {
+		revoked, err := p.revokeOrganizationRole(ctx, msg, userApi.GithubId)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to revoke organization role for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to revoke organization role")
+		}
+
+		if !revoked {
+			p.log.Infof("finish handle message action with id `%s`", msg.RequestId)
+			return nil
+		}
+	} else {
+		err = pqueue.Exec(
+			ctx,
+			p.pqueues.ForLink(msg.Link).SuperUserPQueue,
+			pqueue.NewKey("RemoveUserFromApi", msg.Link, msg.Username, msg.Type),
+			func(ctx context.Context) error {
+				return p.githubClient.RemoveUserFromApi(ctx, msg.Link, msg.Username, msg.Type)
+			},
+			pqueue.NormalPriority,
+		)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to remove user from API for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "some error while removing user from api")
+		}
 	}
 
 	err = p.managerQ.Transaction(func() error {
@@ -105,6 +131,48 @@ func (p *processor) HandleRemoveUserAction(msg data.ModulePayload) error {
 	return nil
 }
 
+// revokeOrganizationRole revokes organization role and tells whether user lost access to organization,
+// otherwise stored permission gets access level the user keeps
+func (p *processor) revokeOrganizationRole(ctx context.Context, msg data.ModulePayload, githubId int64) (bool, error) {
+	err := pqueue.Exec(
+		ctx,
+		p.pqueues.ForLink(msg.Link).SuperUserPQueue,
+		pqueue.NewKey("RevokeOrganizationRoleFromApi", msg.Link, msg.Username, msg.AccessLevel),
+		func(ctx context.Context) error {
+			return p.githubClient.RevokeOrganizationRoleFromApi(ctx, msg.Link, msg.Username, msg.AccessLevel)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to revoke organization role from api")
+	}
+
+	permission, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(msg.Link).UserPQueue,
+		pqueue.NewKey("CheckOrganizationCollaborator", msg.Link, msg.Username),
+		func(ctx context.Context) (*data.Permission, error) {
+			return p.githubClient.CheckOrganizationCollaborator(ctx, msg.Link, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check organization collaborator")
+	}
+
+	if permission == nil || permission.Invitation != nil {
+		return true, nil
+	}
+
+	err = p.permissionsQ.FilterByGithubIds(githubId).FilterByLinks(msg.Link).FilterByTypes(data.Organization).
+		Update(data.PermissionToUpdate{AccessLevel: &permission.AccessLevel})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update permission")
+	}
+
+	return false, nil
+}
+
 func (p *processor) deleteLowerLevelPermissions(githubId int64, link, typeTo string) error {
 	err := p.permissionsQ.FilterByGithubIds(githubId).FilterByTypes(typeTo).FilterByLinks(link).Delete()
 	if err != nil {
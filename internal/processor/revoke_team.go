@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (p *processor) validateRevokeTeam(msg data.ModulePayload) error {
+	return validation.Errors{
+		"link": validation.Validate(msg.Link, validation.Required),
+		"team": validation.Validate(msg.Team, validation.Required),
+	}.Filter()
+}
+
+func (p *processor) HandleRevokeTeamAction(ctx context.Context, msg data.ModulePayload) error {
+	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
+
+	err := p.validateRevokeTeam(msg)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to validate fields for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to validate fields")
+	}
+
+	msg.Link = strings.ToLower(msg.Link)
+	msg.Team = strings.ToLower(msg.Team)
+
+	err = p.checkTeamAndRepository(ctx, msg.Team, msg.Link)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to check team and repository for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to check team and repository")
+	}
+
+	err = pqueue.Exec(
+		ctx,
+		p.pqueues.ForLink(msg.Team).SuperUserPQueue,
+		pqueue.NewKey("RemoveTeamFromRepositoryFromApi", msg.Team, msg.Link),
+		func(ctx context.Context) error {
+			return p.githubClient.RemoveTeamFromRepositoryFromApi(ctx, msg.Team, msg.Link)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to revoke team from API for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "some error while revoking team from api")
+	}
+
+	permission, err := p.teamPermissionsQ.FilterByTeamLinks(msg.Team).FilterByRepoLinks(msg.Link).Get()
+	if err != nil {
+		p.log.WithError(err).Errorf("failed to get team permission from db for message action with id `%s`", msg.RequestId)
+		return errors.Wrap(err, "failed to get team permission from db")
+	}
+
+	//grant could be made outside module and not indexed yet
+	if permission != nil {
+		err = p.teamPermissionsQ.FilterByTeamLinks(msg.Team).FilterByRepoLinks(msg.Link).Delete()
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to delete team permission from db for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to delete team permission from db")
+		}
+	}
+
+	p.log.Infof("finish handle message action with id `%s`", msg.RequestId)
+	return nil
+}
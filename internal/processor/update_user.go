@@ -1,10 +1,10 @@
 package processor
 
 import (
+	"context"
 	"strings"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -18,7 +18,7 @@ func (p *processor) validateUpdateUser(msg data.ModulePayload) error {
 	}.Filter()
 }
 
-func (p *processor) HandleUpdateUserAction(msg data.ModulePayload) error {
+func (p *processor) HandleUpdateUserAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateUpdateUser(msg)
@@ -27,20 +27,29 @@ func (p *processor) HandleUpdateUserAction(msg data.ModulePayload) error {
 		return errors.Wrap(err, "failed to validate fields")
 	}
 	msg.Link = strings.ToLower(msg.Link)
+	msg.AccessLevel = data.NormalizeRole(msg.AccessLevel)
 
-	user, err := p.checkUserExistence(msg.Username)
+	user, err := p.checkUserExistence(ctx, msg.Username)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to check user existence for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "failed to check user existence")
 	}
 
-	msg.Type, err = p.getLinkType(msg.Link, pqueue.NormalPriority)
+	msg.Type, err = p.getLinkType(ctx, msg.Link, pqueue.NormalPriority)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get link type from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting link type api")
 	}
 
-	isHere, err := p.isUserInSubmodule(msg.Link, msg.Username, msg.Type)
+	if msg.Type == data.Repository {
+		err = p.checkRepositoryRole(ctx, msg.Link, msg.AccessLevel)
+		if err != nil {
+			p.log.WithError(err).Errorf("failed to check repository role for message action with id `%s`", msg.RequestId)
+			return errors.Wrap(err, "failed to check repository role")
+		}
+	}
+
+	isHere, err := p.isUserInSubmodule(ctx, msg.Link, msg.Username, msg.Type)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to check user from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while checking user from api")
@@ -50,7 +59,7 @@ func (p *processor) HandleUpdateUserAction(msg data.ModulePayload) error {
 		return errors.New("user is not in submodule")
 	}
 
-	err = p.updateUser(data.Permission{
+	err = p.updateUser(ctx, data.Permission{
 		RequestId:   msg.RequestId,
 		UserId:      user.Id,
 		GithubId:    user.GithubId,
@@ -74,12 +83,16 @@ func (p *processor) HandleUpdateUserAction(msg data.ModulePayload) error {
 	return nil
 }
 
-func (p *processor) updateUser(info data.Permission) error {
-	permission, err := github.GetPermission(
-		p.pqueues.SuperUserPQueue,
-		any(p.githubClient.UpdateUserFromApi),
-		[]any{any(info.Type), any(info.Link), any(info.Username), any(info.AccessLevel)},
-		pqueue.NormalPriority)
+func (p *processor) updateUser(ctx context.Context, info data.Permission) error {
+	permission, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(info.Link).SuperUserPQueue,
+		pqueue.NewKey("UpdateUserFromApi", info.Type, info.Link, info.Username, info.AccessLevel),
+		func(ctx context.Context) (*data.Permission, error) {
+			return p.githubClient.UpdateUserFromApi(ctx, info.Type, info.Link, info.Username, info.AccessLevel)
+		},
+		pqueue.NormalPriority,
+	)
 	if err != nil {
 		return errors.Wrap(err, "some error while updating user from api")
 	}
@@ -104,7 +117,7 @@ func (p *processor) updateUser(info data.Permission) error {
 	return nil
 }
 
-func (p *processor) checkUserExistence(username string) (*data.User, error) {
+func (p *processor) checkUserExistence(ctx context.Context, username string) (*data.User, error) {
 	dbUser, err := p.usersQ.FilterByUsernames(username).Get()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get user from user db")
@@ -114,7 +127,15 @@ func (p *processor) checkUserExistence(username string) (*data.User, error) {
 		return nil, errors.New("no user with such username")
 	}
 
-	userApi, err := github.GetUser(p.pqueues.UserPQueue, any(p.githubClient.GetUserFromApi), []any{any(username)}, pqueue.NormalPriority)
+	userApi, err := pqueue.Submit(
+		ctx,
+		p.pqueues.UserPQueue,
+		pqueue.NewKey("GetUserFromApi", username),
+		func(ctx context.Context) (*data.User, error) {
+			return p.githubClient.GetUserFromApi(ctx, username)
+		},
+		pqueue.NormalPriority,
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "some error while getting user from api")
 	}
@@ -1,6 +1,9 @@
 package processor
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/acs-dl/github-module-svc/internal/data"
 	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
@@ -8,8 +11,16 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
-func (p *processor) getLinkType(link string, priority int) (string, error) {
-	checkType, err := github.GetPermissionWithType(p.pqueues.SuperUserPQueue, any(p.githubClient.FindType), []any{any(link)}, priority)
+func (p *processor) getLinkType(ctx context.Context, link string, priority int) (string, error) {
+	checkType, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("FindType", link),
+		func(ctx context.Context) (*github.TypeSub, error) {
+			return p.githubClient.FindType(ctx, link)
+		},
+		priority,
+	)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get link type")
 	}
@@ -18,25 +29,38 @@ func (p *processor) getLinkType(link string, priority int) (string, error) {
 		return "", errors.New("no type was found ")
 	}
 
-	if validation.Validate(checkType.Type, validation.In(data.Organization, data.Repository)) != nil {
+	if validation.Validate(checkType.Type, validation.In(data.Organization, data.Repository, data.Team)) != nil {
 		return "", errors.Wrap(err, "something wrong with link type")
 	}
 
 	return checkType.Type, nil
 }
 
-func (p *processor) isUserInSubmodule(link, username, typeTo string) (bool, error) {
-	permission, err := github.GetPermission(
-		p.pqueues.SuperUserPQueue,
-		any(p.githubClient.CheckUserFromApi),
-		[]any{any(link), any(username), any(typeTo)},
+func (p *processor) isUserInSubmodule(ctx context.Context, link, username, typeTo string) (bool, error) {
+	permission, err := p.getUserInSubmodule(ctx, link, username, typeTo)
+	if err != nil {
+		return false, err
+	}
+
+	return permission != nil, nil
+}
+
+// getUserInSubmodule returns current permission of user in submodule, nil is returned when user isn't there
+func (p *processor) getUserInSubmodule(ctx context.Context, link, username, typeTo string) (*data.Permission, error) {
+	permission, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("CheckUserFromApi", link, username, typeTo),
+		func(ctx context.Context) (*data.Permission, error) {
+			return p.githubClient.CheckUserFromApi(ctx, link, username, typeTo)
+		},
 		pqueue.NormalPriority,
 	)
 	if err != nil {
-		return false, errors.Wrap(err, "some error while checking link type api")
+		return nil, errors.Wrap(err, "some error while checking link type api")
 	}
 
-	return permission != nil, err
+	return permission, nil
 }
 
 func (p *processor) indexHasParentChild(githubId int64, link string) error {
@@ -55,3 +79,53 @@ func (p *processor) indexHasParentChild(githubId int64, link string) error {
 
 	return nil
 }
+
+// checkRepositoryRole checks that access level is either standard repository role or organization custom role
+func (p *processor) checkRepositoryRole(ctx context.Context, link, accessLevel string) error {
+	if _, ok := data.FindRole(data.Repository, accessLevel); ok {
+		return nil
+	}
+
+	owner := github.LinkOwner(link)
+
+	customRole, err := p.customRolesQ.FilterByLinks(owner).FilterByNames(accessLevel).Get()
+	if err != nil {
+		return errors.Wrap(err, "failed to get custom role")
+	}
+	if customRole != nil {
+		return nil
+	}
+
+	//role could be created after last worker run
+	customRoles, err := pqueue.Submit(
+		ctx,
+		p.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("GetCustomRepositoryRolesFromApi", owner),
+		func(ctx context.Context) ([]data.CustomRole, error) {
+			return p.githubClient.GetCustomRepositoryRolesFromApi(ctx, owner)
+		},
+		pqueue.NormalPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get custom roles from api")
+	}
+
+	for _, role := range customRoles {
+		if role.Name == accessLevel {
+			//custom roles can be cached only for indexed organizations
+			sub, err := p.subsQ.FilterByLinks(owner).Get()
+			if err != nil {
+				return errors.Wrap(err, "failed to get organization sub")
+			}
+			if sub != nil {
+				if err = p.customRolesQ.Upsert(role); err != nil {
+					return errors.Wrap(err, "failed to upsert custom role")
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("unknown repository role `%s`", accessLevel))
+}
@@ -1,11 +1,11 @@
 package processor
 
 import (
+	"context"
 	"strconv"
 	"time"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
-	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -18,7 +18,7 @@ func (p *processor) validateVerifyUser(msg data.ModulePayload) error {
 	}.Filter()
 }
 
-func (p *processor) HandleVerifyUserAction(msg data.ModulePayload) error {
+func (p *processor) HandleVerifyUserAction(ctx context.Context, msg data.ModulePayload) error {
 	p.log.Infof("start handle message action with id `%s`", msg.RequestId)
 
 	err := p.validateVerifyUser(msg)
@@ -33,7 +33,15 @@ func (p *processor) HandleVerifyUserAction(msg data.ModulePayload) error {
 		return errors.Wrap(err, "failed to parse user id")
 	}
 
-	userApi, err := github.GetUser(p.pqueues.UserPQueue, any(p.githubClient.GetUserFromApi), []any{any(msg.Username)}, pqueue.NormalPriority)
+	userApi, err := pqueue.Submit(
+		ctx,
+		p.pqueues.UserPQueue,
+		pqueue.NewKey("GetUserFromApi", msg.Username),
+		func(ctx context.Context) (*data.User, error) {
+			return p.githubClient.GetUserFromApi(ctx, msg.Username)
+		},
+		pqueue.NormalPriority,
+	)
 	if err != nil {
 		p.log.WithError(err).Errorf("failed to get user from API for message action with id `%s`", msg.RequestId)
 		return errors.Wrap(err, "some error while getting user from api")
@@ -27,10 +27,32 @@ const (
 	VerifyUserAction = "verify_user"
 	DeleteUserAction = "delete_user"
 
+	GrantTeamAction  = "grant_team"
+	RevokeTeamAction = "revoke_team"
+
+	CancelInvitationAction = "cancel_invitation"
+
+	ConvertToOutsideAction          = "convert_to_outside"
+	InviteOutsideCollaboratorAction = "invite_outside_collaborator"
+
 	RefreshModuleAction    = "refresh_module"
 	RefreshSubmoduleAction = "refresh_submodule"
 )
 
+// mutatingActions change GitHub state, their messages are stored as jobs before they are acked
+var mutatingActions = map[string]bool{
+	AddUserAction:                   true,
+	UpdateUserAction:                true,
+	RemoveUserAction:                true,
+	DeleteUserAction:                true,
+	VerifyUserAction:                true,
+	GrantTeamAction:                 true,
+	RevokeTeamAction:                true,
+	CancelInvitationAction:          true,
+	ConvertToOutsideAction:          true,
+	InviteOutsideCollaboratorAction: true,
+}
+
 type Receiver struct {
 	subscriber  *amqp.Subscriber
 	topic       string
@@ -38,30 +60,48 @@ type Receiver struct {
 	processor   processor.Processor
 	worker      *worker.Worker
 	responseQ   data.Responses
+	jobsQ       data.Jobs
 	runnerDelay time.Duration
+	// deadline limits handling of one message, so it doesn't wait in queue forever
+	deadline time.Duration
 }
 
-var handleActions = map[string]func(r *Receiver, msg data.ModulePayload) error{
-	AddUserAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.processor.HandleAddUserAction(msg)
+var handleActions = map[string]func(ctx context.Context, r *Receiver, msg data.ModulePayload) error{
+	AddUserAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleAddUserAction(ctx, msg)
+	},
+	UpdateUserAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleUpdateUserAction(ctx, msg)
+	},
+	RemoveUserAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleRemoveUserAction(ctx, msg)
+	},
+	DeleteUserAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleDeleteUserAction(ctx, msg)
 	},
-	UpdateUserAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.processor.HandleUpdateUserAction(msg)
+	VerifyUserAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleVerifyUserAction(ctx, msg)
 	},
-	RemoveUserAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.processor.HandleRemoveUserAction(msg)
+	GrantTeamAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleGrantTeamAction(ctx, msg)
 	},
-	DeleteUserAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.processor.HandleDeleteUserAction(msg)
+	RevokeTeamAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleRevokeTeamAction(ctx, msg)
 	},
-	VerifyUserAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.processor.HandleVerifyUserAction(msg)
+	CancelInvitationAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleCancelInvitationAction(ctx, msg)
 	},
-	RefreshModuleAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.worker.ProcessPermissions(context.Background())
+	ConvertToOutsideAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleConvertToOutsideAction(ctx, msg)
 	},
-	RefreshSubmoduleAction: func(r *Receiver, msg data.ModulePayload) error {
-		return r.worker.RefreshSubmodules(msg)
+	InviteOutsideCollaboratorAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.processor.HandleInviteOutsideCollaboratorAction(ctx, msg)
+	},
+	RefreshModuleAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.worker.ProcessPermissions(ctx)
+	},
+	RefreshSubmoduleAction: func(ctx context.Context, r *Receiver, msg data.ModulePayload) error {
+		return r.worker.RefreshSubmodules(ctx, msg)
 	},
 }
 
@@ -73,18 +113,40 @@ func NewReceiverAsInterface(cfg config.Config, ctx context.Context) interface{}
 		processor:   processor.ProcessorInstance(ctx),
 		worker:      worker.WorkerInstance(ctx),
 		responseQ:   postgres.NewResponsesQ(cfg.DB()),
+		jobsQ:       postgres.NewJobsQ(cfg.DB()),
 		runnerDelay: cfg.Runners().Receiver,
+		deadline:    cfg.Deadlines().Receiver,
 	})
 }
 
 func (r *Receiver) Run(ctx context.Context) {
-	go running.WithBackOff(ctx, r.log,
-		ServiceName,
-		r.listenMessages,
-		r.runnerDelay,
-		r.runnerDelay,
-		r.runnerDelay,
-	)
+	go func() {
+		//jobs interrupted by restart are older than new messages, so they are finished first
+		r.resumeJobs(ctx)
+
+		running.WithBackOff(ctx, r.log,
+			ServiceName,
+			r.listenMessages,
+			r.runnerDelay,
+			r.runnerDelay,
+			r.runnerDelay,
+		)
+	}()
+}
+
+func (r *Receiver) resumeJobs(ctx context.Context) {
+	jobs, err := r.jobsQ.New().FilterByStatuses(data.JobQueued, data.JobRunning).Select()
+	if err != nil {
+		r.log.WithError(err).Error("failed to select unfinished jobs")
+		return
+	}
+
+	r.log.Infof("resuming %v unfinished jobs", len(jobs))
+	for _, job := range jobs {
+		if err = r.processJob(ctx, job); err != nil {
+			r.log.WithError(err).Errorf("failed to process job `%s`", job.Id)
+		}
+	}
 }
 
 func (r *Receiver) listenMessages(ctx context.Context) error {
@@ -105,28 +167,38 @@ func (r *Receiver) subscribeForTopic(ctx context.Context, topic string) error {
 			return nil
 		case msg := <-msgChan:
 			r.log.Info("received message ", msg.UUID)
-			err = r.processMessage(msg)
+			job, err := r.storeJob(msg)
 			if err != nil {
-				r.log.WithError(err).Error("failed to process message ", msg.UUID)
+				//message is delivered again, so it isn't lost when job can't be stored
+				r.log.WithError(err).Error("failed to store job of message ", msg.UUID)
+				msg.Nack()
+				continue
 			}
 			msg.Ack()
+
+			if job == nil {
+				continue
+			}
+			if err = r.processJob(ctx, *job); err != nil {
+				r.log.WithError(err).Error("failed to process message ", msg.UUID)
+			}
 		}
 	}
 }
 
-func (r *Receiver) HandleNewMessage(msg data.ModulePayload) error {
+func (r *Receiver) HandleNewMessage(ctx context.Context, msg data.ModulePayload) error {
 	r.log.Infof("handling message with id `%s`", msg.RequestId)
 
 	err := validation.Errors{
 		"action": validation.Validate(msg.Action, validation.Required),
 	}.Filter()
 	if err != nil {
-		r.log.WithError(err).Error("no such action to handle for message with id `%s`", msg.RequestId)
+		r.log.WithError(err).Errorf("no such action to handle for message with id `%s`", msg.RequestId)
 		return errors.New("no such action " + msg.Action + " to handle for message with id " + msg.RequestId)
 	}
 
 	requestHandler := handleActions[msg.Action]
-	if err = requestHandler(r, msg); err != nil {
+	if err = requestHandler(ctx, r, msg); err != nil {
 		r.log.WithError(err).Errorf("failed to handle message with id `%s`", msg.RequestId)
 		return err
 	}
@@ -135,37 +207,119 @@ func (r *Receiver) HandleNewMessage(msg data.ModulePayload) error {
 	return nil
 }
 
-func (r *Receiver) processMessage(msg *message.Message) error {
-	r.log.Info("started processing message ", msg.UUID)
-
+// storeJob stores message with mutating action as job, nil job is returned for message that can't be handled
+func (r *Receiver) storeJob(msg *message.Message) (*data.Job, error) {
 	var queueOutput data.ModulePayload
 	err := json.Unmarshal(msg.Payload, &queueOutput)
 	if err != nil {
-		r.log.WithError(err).Errorf("failed to unmarshal message", msg.UUID)
-		return errors.Wrap(err, "failed to unmarshal message "+msg.UUID)
+		r.log.WithError(err).Errorf("failed to unmarshal message `%s`", msg.UUID)
+		return nil, nil
+	}
+
+	job := data.Job{
+		Id:      msg.UUID,
+		Action:  queueOutput.Action,
+		Payload: json.RawMessage(msg.Payload),
+		Status:  data.JobQueued,
+	}
+	if !mutatingActions[job.Action] {
+		return &job, nil
+	}
+
+	if err = r.jobsQ.New().Insert(job); err != nil {
+		return nil, errors.Wrap(err, "failed to insert job")
+	}
+
+	return &job, nil
+}
+
+// processJob handles job at least once: job that was started but not finished before restart is handled again,
+// so GitHub calls of mutating actions have to be idempotent
+func (r *Receiver) processJob(ctx context.Context, job data.Job) error {
+	r.log.Info("started processing message ", job.Id)
+
+	var queueOutput data.ModulePayload
+	err := json.Unmarshal(job.Payload, &queueOutput)
+	if err != nil {
+		r.log.WithError(err).Errorf("failed to unmarshal message `%s`", job.Id)
+		return errors.Wrap(err, "failed to unmarshal message "+job.Id)
+	}
+	queueOutput.RequestId = job.Id
+
+	durable := mutatingActions[job.Action]
+	if durable {
+		//response is stored when job was handled but not finished before restart
+		response, err := r.responseQ.New().FilterByIds(job.Id).Get()
+		if err != nil {
+			return errors.Wrap(err, "failed to get response "+job.Id)
+		}
+		if response != nil {
+			return r.finishJob(job, response.Status, response.Error)
+		}
+
+		attempts := job.Attempts + 1
+		status := data.JobRunning
+		err = r.jobsQ.New().FilterByIds(job.Id).Update(data.JobToUpdate{
+			Status:   &status,
+			Attempts: &attempts,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to mark job as running "+job.Id)
+		}
 	}
-	queueOutput.RequestId = msg.UUID
 
 	var responseStatus = "success"
 	var errMsg = ""
-	err = r.HandleNewMessage(queueOutput)
+	var errCode = ""
+	handleCtx, cancel := context.WithTimeout(ctx, r.deadline)
+	err = r.HandleNewMessage(handleCtx, queueOutput)
+	cancel()
+	if err != nil && ctx.Err() != nil {
+		//job is resumed after restart
+		return errors.Wrap(err, "service stopped before message was handled "+job.Id)
+	}
 	if err != nil {
 		responseStatus = "failure"
 		errMsg = err.Error()
-		r.log.WithError(err).Error("failed to process message ", msg.UUID)
+		errCode = processor.FailureCode(err)
+		r.log.WithError(err).Error("failed to process message ", job.Id)
 	}
 
 	err = r.responseQ.Insert(data.Response{
-		ID:      msg.UUID,
-		Status:  responseStatus,
-		Error:   errMsg,
-		Payload: json.RawMessage(msg.Payload),
+		ID:        job.Id,
+		Status:    responseStatus,
+		Error:     errMsg,
+		ErrorCode: errCode,
+		Payload:   job.Payload,
+	})
+	if err != nil {
+		r.log.WithError(err).Errorf("failed to create response `%s`", job.Id)
+		return errors.Wrap(err, "failed to create response "+job.Id)
+	}
+
+	if durable {
+		if err = r.finishJob(job, responseStatus, errMsg); err != nil {
+			return err
+		}
+	}
+
+	r.log.Info("finished processing message ", job.Id)
+	return nil
+}
+
+func (r *Receiver) finishJob(job data.Job, responseStatus, errMsg string) error {
+	status := data.JobDone
+	if responseStatus != "success" {
+		status = data.JobFailed
+	}
+
+	err := r.jobsQ.New().FilterByIds(job.Id).Update(data.JobToUpdate{
+		Status: &status,
+		Error:  &errMsg,
 	})
 	if err != nil {
-		r.log.WithError(err).Errorf("failed to create response", msg.UUID)
-		return errors.Wrap(err, "failed to create response "+msg.UUID)
+		return errors.Wrap(err, "failed to finish job "+job.Id)
 	}
 
-	r.log.Info("finished processing message ", msg.UUID)
 	return nil
 }
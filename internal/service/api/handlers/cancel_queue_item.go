@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/internal/service/api/requests"
+	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+func CancelQueueItem(w http.ResponseWriter, r *http.Request) {
+	request, err := requests.NewQueueItemRequest(r)
+	if err != nil {
+		background.Log(r).WithError(err).Info("wrong request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	queue, ok := pqueue.PQueuesInstance(background.ParentContext(r.Context())).Queue(request.Queue)
+	if !ok {
+		background.Log(r).Infof("no queue `%s`", request.Queue)
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	err = queue.Cancel(uint64(request.Id))
+	if err == pqueue.ErrItemNotPending {
+		background.Log(r).Infof("no item `%d` waiting in queue `%s`", request.Id, request.Queue)
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+	if err != nil {
+		background.Log(r).WithError(err).Errorf("failed to cancel item `%d`", request.Id)
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	background.Log(r).Infof("item `%d` in queue `%s` is cancelled", request.Id, request.Queue)
+	w.WriteHeader(http.StatusNoContent)
+}
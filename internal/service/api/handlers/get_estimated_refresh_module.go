@@ -15,7 +15,7 @@ func GetEstimatedRefreshModule(w http.ResponseWriter, r *http.Request) {
 	parentContext := background.ParentContext(r.Context())
 	workerInstance := *worker.WorkerInstance(parentContext)
 
-	pqueueRequestsAmount := int64(pqueue.PQueuesInstance(parentContext).SuperUserPQueue.Len() + pqueue.PQueuesInstance(parentContext).UserPQueue.Len())
+	pqueueRequestsAmount := int64(pqueue.PQueuesInstance(parentContext).Len())
 	requestsTimeLimit := background.Config(parentContext).RateLimit().TimeLimit
 	requestsAmountLimit := background.Config(parentContext).RateLimit().RequestsAmount
 
@@ -40,7 +40,7 @@ func GetEstimatedRefreshSubmodule(w http.ResponseWriter, r *http.Request) {
 
 	parentContext := background.ParentContext(r.Context())
 
-	pqueueRequestsAmount := int64(pqueue.PQueuesInstance(parentContext).SuperUserPQueue.Len() + pqueue.PQueuesInstance(parentContext).UserPQueue.Len())
+	pqueueRequestsAmount := int64(pqueue.PQueuesInstance(parentContext).Len())
 
 	requestsTimeLimit := background.Config(parentContext).RateLimit().TimeLimit
 	requestsAmountLimit := background.Config(parentContext).RateLimit().RequestsAmount
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
 	"github.com/acs-dl/github-module-svc/internal/service/api/models"
@@ -69,9 +70,37 @@ func GetPermissions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	teamPermissions, err := getTeamPermissions(r, request, userIds, usernames)
+	if err != nil {
+		background.Log(r).WithError(err).Error("failed to get team permissions")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
 	response := models.NewUserPermissionListResponse(permissions)
 	response.Meta.TotalCount = amount
 	response.Links = data.GetOffsetLinksForPGParams(r, request.OffsetPageParams)
+	response.Included = models.NewTeamPermissionIncluded(teamPermissions)
 
 	ape.Render(w, response)
 }
+
+// getTeamPermissions returns repository access given through teams, only for requests narrowed to link or user
+func getTeamPermissions(r *http.Request, request requests.GetPermissionsRequest, userIds []int64, usernames []string) ([]data.TeamPermission, error) {
+	if request.Link == nil && len(userIds) == 0 && len(usernames) == 0 {
+		return nil, nil
+	}
+
+	statement := background.TeamPermissionsQ(r)
+	if request.Link != nil {
+		statement = statement.FilterByRepoLinks(strings.ToLower(*request.Link))
+	}
+	if len(userIds) != 0 {
+		statement = statement.FilterByUserIds(userIds...)
+	}
+	if len(usernames) != 0 {
+		statement = statement.FilterByUsernames(usernames...)
+	}
+
+	return statement.Select()
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/internal/service/api/models"
+	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/ape"
+)
+
+func GetQueueWaitTimes(w http.ResponseWriter, r *http.Request) {
+	waitTimes := pqueue.PQueuesInstance(background.ParentContext(r.Context())).WaitTimes()
+
+	ape.Render(w, models.NewQueueWaitTimeListResponse(waitTimes))
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/internal/service/api/models"
+	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/ape"
+)
+
+func GetQueues(w http.ResponseWriter, r *http.Request) {
+	queues := pqueue.PQueuesInstance(background.ParentContext(r.Context())).Queues()
+
+	ape.Render(w, models.NewQueueListResponse(queues))
+}
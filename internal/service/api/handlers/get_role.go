@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/github"
 	"github.com/acs-dl/github-module-svc/internal/service/api/models"
 	"github.com/acs-dl/github-module-svc/internal/service/api/requests"
 	"github.com/acs-dl/github-module-svc/internal/service/background"
@@ -25,12 +27,31 @@ func GetRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	name := data.Roles[*request.AccessLevel]
-	if name == "" {
+	for _, scope := range []string{data.Repository, data.Organization, data.Team} {
+		role, ok := data.FindRole(scope, *request.AccessLevel)
+		if ok {
+			ape.Render(w, models.NewRoleResponse(role.Name, role.Value))
+			return
+		}
+	}
+
+	customRolesQ := background.CustomRolesQ(r).FilterByNames(*request.AccessLevel)
+	if request.Link != nil {
+		customRolesQ = customRolesQ.FilterByLinks(github.LinkOwner(strings.ToLower(*request.Link)))
+	}
+
+	customRole, err := customRolesQ.Get()
+	if err != nil {
+		background.Log(r).WithError(err).Errorf("failed to get custom role `%s`", *request.AccessLevel)
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	if customRole == nil {
 		background.Log(r).Errorf("no such access level `%s`", *request.AccessLevel)
 		ape.RenderErr(w, problems.NotFound())
 		return
 	}
 
-	ape.Render(w, models.NewRoleResponse(data.Roles[*request.AccessLevel], *request.AccessLevel))
+	ape.Render(w, models.NewRoleResponse(customRole.Name, customRole.Name))
 }
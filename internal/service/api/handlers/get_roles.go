@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -48,12 +49,20 @@ func GetRoles(w http.ResponseWriter, r *http.Request) {
 	if permission != nil {
 		owned := data.OrganizationOwned
 		if permission.Type == data.Repository {
-			owned, err = github.GetString(
-				pqueue.PQueuesInstance(background.ParentContext(r.Context())).SuperUserPQueue,
-				any(githubClient.FindRepositoryOwner),
-				[]any{any(link)},
+			owned, err = pqueue.Submit(
+				r.Context(),
+				pqueue.PQueuesInstance(background.ParentContext(r.Context())).ForLink(link).UserPQueue,
+				pqueue.NewKey("FindRepositoryOwner", link),
+				func(ctx context.Context) (string, error) {
+					return githubClient.FindRepositoryOwner(ctx, link)
+				},
 				pqueue.HighPriority,
 			)
+			if r.Context().Err() != nil {
+				//timeout middleware responds when deadline is exceeded
+				background.Log(r).WithError(err).Warn("request is done before repository owner type was got")
+				return
+			}
 			if err != nil {
 				background.Log(r).WithError(err).Errorf("failed to get repository owner type")
 				ape.RenderErr(w, problems.InternalError())
@@ -61,11 +70,22 @@ func GetRoles(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		ape.Render(w, models.NewRolesResponse(true, permission.Type, owned, permission.AccessLevel))
+		customRoles, err := background.CustomRolesQ(r).FilterByLinks(github.LinkOwner(link)).Select()
+		if err != nil {
+			background.Log(r).WithError(err).Errorf("failed to get custom roles")
+			ape.RenderErr(w, problems.InternalError())
+			return
+		}
+
+		ape.Render(w, models.NewRolesResponse(true, permission.Type, owned, permission.AccessLevel, customRoles))
 		return
 	}
 
 	response, err := checkRemoteUser(r, *request.Username, link)
+	if r.Context().Err() != nil {
+		background.Log(r).WithError(err).Warn("request is done before remote user was checked")
+		return
+	}
 	if err != nil {
 		background.Log(r).WithError(err).Errorf("failed to check remote user")
 		ape.RenderErr(w, problems.InternalError())
@@ -84,10 +104,13 @@ func GetRoles(w http.ResponseWriter, r *http.Request) {
 func checkRemoteUser(r *http.Request, username, link string) (*resources.RolesResponse, error) {
 	githubClient := github.GithubClientInstance(background.ParentContext(r.Context()))
 
-	user, err := github.GetUser(
+	user, err := pqueue.Submit(
+		r.Context(),
 		pqueue.PQueuesInstance(background.ParentContext(r.Context())).UserPQueue,
-		any(githubClient.GetUserFromApi),
-		[]any{any(username)},
+		pqueue.NewKey("GetUserFromApi", username),
+		func(ctx context.Context) (*data.User, error) {
+			return githubClient.GetUserFromApi(ctx, username)
+		},
 		pqueue.HighPriority,
 	)
 	if err != nil {
@@ -99,10 +122,13 @@ func checkRemoteUser(r *http.Request, username, link string) (*resources.RolesRe
 		return nil, nil
 	}
 
-	typeSub, err := github.GetPermissionWithType(
-		pqueue.PQueuesInstance(background.ParentContext(r.Context())).SuperUserPQueue,
-		any(githubClient.FindType),
-		[]any{any(link)},
+	typeSub, err := pqueue.Submit(
+		r.Context(),
+		pqueue.PQueuesInstance(background.ParentContext(r.Context())).ForLink(link).UserPQueue,
+		pqueue.NewKey("FindType", link),
+		func(ctx context.Context) (*github.TypeSub, error) {
+			return githubClient.FindType(ctx, link)
+		},
 		pqueue.HighPriority,
 	)
 	if err != nil {
@@ -116,10 +142,13 @@ func checkRemoteUser(r *http.Request, username, link string) (*resources.RolesRe
 
 	owned := data.OrganizationOwned
 	if typeSub.Type == data.Repository {
-		owned, err = github.GetString(
-			pqueue.PQueuesInstance(background.ParentContext(r.Context())).SuperUserPQueue,
-			any(githubClient.FindRepositoryOwner),
-			[]any{any(link)},
+		owned, err = pqueue.Submit(
+			r.Context(),
+			pqueue.PQueuesInstance(background.ParentContext(r.Context())).ForLink(link).UserPQueue,
+			pqueue.NewKey("FindRepositoryOwner", link),
+			func(ctx context.Context) (string, error) {
+				return githubClient.FindRepositoryOwner(ctx, link)
+			},
 			pqueue.HighPriority,
 		)
 		if err != nil {
@@ -127,10 +156,18 @@ func checkRemoteUser(r *http.Request, username, link string) (*resources.RolesRe
 		}
 	}
 
-	permission, err := github.GetPermission(
-		pqueue.PQueuesInstance(background.ParentContext(r.Context())).SuperUserPQueue,
-		any(githubClient.CheckUserFromApi),
-		[]any{any(link), any(username), any(typeSub.Type)},
+	customRoles, err := background.CustomRolesQ(r).FilterByLinks(github.LinkOwner(link)).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get custom roles")
+	}
+
+	permission, err := pqueue.Submit(
+		r.Context(),
+		pqueue.PQueuesInstance(background.ParentContext(r.Context())).ForLink(link).UserPQueue,
+		pqueue.NewKey("CheckUserFromApi", link, username, typeSub.Type),
+		func(ctx context.Context) (*data.Permission, error) {
+			return githubClient.CheckUserFromApi(ctx, link, username, typeSub.Type)
+		},
 		pqueue.HighPriority,
 	)
 	if err != nil {
@@ -138,10 +175,10 @@ func checkRemoteUser(r *http.Request, username, link string) (*resources.RolesRe
 	}
 
 	if permission == nil {
-		response := models.NewRolesResponse(true, typeSub.Type, owned, "")
+		response := models.NewRolesResponse(true, typeSub.Type, owned, "", customRoles)
 		return &response, nil
 	}
 
-	response := models.NewRolesResponse(true, typeSub.Type, owned, permission.AccessLevel)
+	response := models.NewRolesResponse(true, typeSub.Type, owned, permission.AccessLevel, customRoles)
 	return &response, nil
 }
@@ -11,7 +11,7 @@ import (
 func GetRolesMap(w http.ResponseWriter, r *http.Request) {
 	result := newModuleRolesResponse()
 
-	for key, val := range data.Roles {
+	for key, val := range data.RoleNames() {
 		result.Data.Attributes[key] = val
 	}
 
@@ -10,9 +10,14 @@ import (
 func GetUserRolesMap(w http.ResponseWriter, r *http.Request) {
 	result := newModuleRolesResponse()
 
-	result.Data.Attributes["super_admin"] = data.Roles["admin"]
-	result.Data.Attributes["admin"] = data.Roles["member"]
-	result.Data.Attributes["user"] = data.Roles["read"]
+	result.Data.Attributes["owner"] = data.ModuleOwnerName
+	result.Data.Attributes["super_admin"] = data.RoleName(data.RoleAdmin)
+	result.Data.Attributes["admin"] = data.RoleName(data.RoleMember)
+	result.Data.Attributes["user"] = data.RoleName(data.RoleRead)
+
+	for _, role := range data.OrganizationRoles {
+		result.Data.Attributes[role] = data.RoleName(role)
+	}
 
 	ape.Render(w, result)
 }
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/acs-dl/github-module-svc/internal/data"
 	"github.com/acs-dl/github-module-svc/internal/github"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	"github.com/acs-dl/github-module-svc/internal/service/api/models"
 	"github.com/acs-dl/github-module-svc/internal/service/api/requests"
 	"github.com/acs-dl/github-module-svc/internal/service/background"
@@ -36,7 +39,22 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err = github.GithubClientInstance(background.ParentContext(r.Context())).SearchByFromApi(username)
+	//search budget is small and paced apart from core one, interactive calls are made from its reserve
+	parentContext := background.ParentContext(r.Context())
+	users, err = pqueue.Submit(
+		r.Context(),
+		pqueue.PQueuesInstance(parentContext).UserPQueue,
+		pqueue.NewKey("SearchByFromApi", username).Spending(data.SearchRateLimit),
+		func(ctx context.Context) ([]data.User, error) {
+			return github.GithubClientInstance(parentContext).SearchByFromApi(ctx, username)
+		},
+		pqueue.HighPriority,
+	)
+	if r.Context().Err() != nil {
+		//timeout middleware responds when deadline is exceeded
+		background.Log(r).WithError(err).Warnf("request is done before users were got from api by `%s`", username)
+		return
+	}
 	if err != nil {
 		background.Log(r).WithError(err).Infof("failed to get users from api by `%s`", username)
 		ape.RenderErr(w, problems.InternalError())
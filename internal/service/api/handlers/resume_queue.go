@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/internal/service/api/requests"
+	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+func ResumeQueue(w http.ResponseWriter, r *http.Request) {
+	name, err := requests.RetrieveQueue(r)
+	if err != nil {
+		background.Log(r).WithError(err).Info("wrong request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	queue, ok := pqueue.PQueuesInstance(background.ParentContext(r.Context())).Queue(name)
+	if !ok {
+		background.Log(r).Infof("no queue `%s`", name)
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	queue.Resume()
+
+	background.Log(r).Infof("queue `%s` is resumed", name)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/internal/service/api/requests"
+	"github.com/acs-dl/github-module-svc/internal/service/background"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+func UpdateQueueItem(w http.ResponseWriter, r *http.Request) {
+	request, err := requests.NewUpdateQueueItemRequest(r)
+	if err != nil {
+		background.Log(r).WithError(err).Info("wrong request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	queue, ok := pqueue.PQueuesInstance(background.ParentContext(r.Context())).Queue(request.Queue)
+	if !ok {
+		background.Log(r).Infof("no queue `%s`", request.Queue)
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	err = queue.SetPriority(uint64(request.Id), int(request.Data.Attributes.Priority))
+	if err == pqueue.ErrItemNotPending {
+		background.Log(r).Infof("no item `%d` waiting in queue `%s`", request.Id, request.Queue)
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+	if err != nil {
+		background.Log(r).WithError(err).Errorf("failed to change priority of item `%d`", request.Id)
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	background.Log(r).Infof("priority of item `%d` in queue `%s` is changed to %d",
+		request.Id, request.Queue, request.Data.Attributes.Priority)
+	w.WriteHeader(http.StatusNoContent)
+}
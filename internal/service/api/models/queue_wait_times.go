@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/resources"
+)
+
+func NewQueueWaitTimeModel(waitTimes pqueue.WaitTimes) resources.QueueWaitTime {
+	class := pqueue.ClassName(waitTimes.Priority)
+
+	//histogram is cumulative as metrics systems expect it
+	buckets := make([]resources.WaitTimeBucket, 0, len(waitTimes.Buckets))
+	count := int64(0)
+	for i, bucketCount := range waitTimes.Buckets {
+		count += int64(bucketCount)
+
+		le := "+Inf"
+		if i < len(pqueue.WaitTimeBounds) {
+			le = pqueue.WaitTimeBounds[i].String()
+		}
+
+		buckets = append(buckets, resources.WaitTimeBucket{
+			Le:    le,
+			Count: count,
+		})
+	}
+
+	return resources.QueueWaitTime{
+		Key: resources.Key{
+			ID:   class,
+			Type: resources.QUEUE_WAIT_TIME,
+		},
+		Attributes: resources.QueueWaitTimeAttributes{
+			Class:    class,
+			Priority: int64(waitTimes.Priority),
+			Count:    int64(waitTimes.Count),
+			Sum:      waitTimes.Sum.String(),
+			Buckets:  buckets,
+		},
+	}
+}
+
+func NewQueueWaitTimeListResponse(waitTimes []pqueue.WaitTimes) resources.QueueWaitTimeListResponse {
+	list := make([]resources.QueueWaitTime, 0, len(waitTimes))
+	for _, classWaitTimes := range waitTimes {
+		list = append(list, NewQueueWaitTimeModel(classWaitTimes))
+	}
+
+	return resources.QueueWaitTimeListResponse{
+		Data: list,
+	}
+}
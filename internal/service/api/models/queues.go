@@ -0,0 +1,70 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/resources"
+)
+
+func NewQueueModel(name string, queue *pqueue.PriorityQueue) resources.Queue {
+	return resources.Queue{
+		Key: resources.Key{
+			ID:   name,
+			Type: resources.QUEUE,
+		},
+		Attributes: resources.QueueAttributes{
+			Paused: queue.Paused(),
+			Length: int64(queue.Len()),
+		},
+	}
+}
+
+func NewQueueListResponse(queues map[string]*pqueue.PriorityQueue) resources.QueueListResponse {
+	names := make([]string, 0, len(queues))
+	for name := range queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]resources.Queue, 0, len(names))
+	for _, name := range names {
+		list = append(list, NewQueueModel(name, queues[name]))
+	}
+
+	return resources.QueueListResponse{
+		Data: list,
+	}
+}
+
+func NewQueueItemModel(queue string, item pqueue.QueuedItem) resources.QueueItem {
+	return resources.QueueItem{
+		Key: resources.Key{
+			ID:   strconv.FormatUint(item.Sequence, 10),
+			Type: resources.QUEUE_ITEM,
+		},
+		Attributes: resources.QueueItemAttributes{
+			Queue:      queue,
+			Method:     item.Method,
+			Args:       item.Args,
+			Resource:   item.Resource,
+			Priority:   int64(item.Priority),
+			Class:      pqueue.ClassName(item.Priority),
+			EnqueuedAt: item.EnqueuedAt,
+			Amount:     int64(item.Amount),
+			Position:   int64(item.Position),
+		},
+	}
+}
+
+func NewQueueItemListResponse(queue string, items []pqueue.QueuedItem) resources.QueueItemListResponse {
+	list := make([]resources.QueueItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, NewQueueItemModel(queue, item))
+	}
+
+	return resources.QueueItemListResponse{
+		Data: list,
+	}
+}
@@ -5,23 +5,6 @@ import (
 	"github.com/acs-dl/github-module-svc/resources"
 )
 
-var orgRepoRoles = []resources.AccessLevel{
-	{Name: "Read", Value: "read"},
-	{Name: "Triage", Value: "triage"},
-	{Name: "Write", Value: "write"},
-	{Name: "Maintain", Value: "maintain"},
-	{Name: "Admin", Value: "admin"},
-}
-
-var userRepoRoles = []resources.AccessLevel{
-	{Name: "Write", Value: "write"},
-}
-
-var orgRoles = []resources.AccessLevel{
-	{Name: "Member", Value: "member"},
-	{Name: "Admin", Value: "admin"},
-}
-
 func NewRolesModel(found bool, roles []resources.AccessLevel) resources.Roles {
 	result := resources.Roles{
 		Key: resources.Key{
@@ -37,30 +20,50 @@ func NewRolesModel(found bool, roles []resources.AccessLevel) resources.Roles {
 	return result
 }
 
-func NewRolesResponse(found bool, typeTo, owned, current string) resources.RolesResponse {
+func NewRolesResponse(found bool, typeTo, owned, current string, customRoles []data.CustomRole) resources.RolesResponse {
 	if !found {
 		return resources.RolesResponse{
 			Data: NewRolesModel(found, make([]resources.AccessLevel, 0)),
 		}
 	}
 
-	if typeTo == data.Organization {
-		return resources.RolesResponse{
-			Data: NewRolesModel(found, newRolesArray(current, orgRoles)),
-		}
+	roles := data.RolesOf(typeTo)
+	if typeTo == data.Repository && owned == data.UserOwned {
+		//user owned repositories have the only role for collaborators
+		role, _ := data.FindRole(data.Repository, data.RoleWrite)
+		roles = []data.Role{role}
 	}
 
-	if owned == data.UserOwned {
-		return resources.RolesResponse{
-			Data: NewRolesModel(found, newRolesArray(current, userRepoRoles)),
-		}
+	levels := newAccessLevels(roles)
+	if typeTo == data.Repository && owned != data.UserOwned {
+		levels = append(levels, newCustomRoles(customRoles)...)
 	}
 
 	return resources.RolesResponse{
-		Data: NewRolesModel(found, newRolesArray(current, orgRepoRoles)),
+		Data: NewRolesModel(found, newRolesArray(data.NormalizeRole(current), levels)),
 	}
 }
 
+func newAccessLevels(roles []data.Role) []resources.AccessLevel {
+	result := make([]resources.AccessLevel, 0, len(roles))
+
+	for _, role := range roles {
+		result = append(result, resources.AccessLevel{Name: role.Name, Value: role.Value})
+	}
+
+	return result
+}
+
+func newCustomRoles(customRoles []data.CustomRole) []resources.AccessLevel {
+	result := make([]resources.AccessLevel, 0, len(customRoles))
+
+	for _, role := range customRoles {
+		result = append(result, resources.AccessLevel{Name: role.Name, Value: role.Name})
+	}
+
+	return result
+}
+
 func newRolesArray(current string, roles []resources.AccessLevel) []resources.AccessLevel {
 	result := make([]resources.AccessLevel, 0)
 
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/resources"
+)
+
+func NewTeamPermissionModel(permission data.TeamPermission) resources.TeamPermission {
+	return resources.TeamPermission{
+		Key: resources.Key{
+			ID:   permission.TeamLink + ":" + permission.RepoLink,
+			Type: resources.TEAM_PERMISSION,
+		},
+		Attributes: resources.TeamPermissionAttributes{
+			Team: permission.TeamLink,
+			Link: permission.RepoLink,
+			AccessLevel: resources.AccessLevel{
+				Name:  data.RoleName(permission.AccessLevel),
+				Value: permission.AccessLevel,
+			},
+		},
+	}
+}
+
+func NewTeamPermissionIncluded(permissions []data.TeamPermission) resources.Included {
+	var result resources.Included
+	for _, permission := range permissions {
+		model := NewTeamPermissionModel(permission)
+		result.Add(&model)
+	}
+	return result
+}
@@ -8,9 +8,9 @@ import (
 )
 
 func NewUserModel(user data.User, id int) resources.User {
-	accessLevel := data.Roles[""]
+	accessLevel := data.RoleName("")
 	if user.AccessLevel != nil {
-		accessLevel = data.Roles[*user.AccessLevel]
+		accessLevel = data.RoleName(*user.AccessLevel)
 	}
 	result := resources.User{
 		Key: resources.Key{
@@ -27,11 +27,12 @@ func NewUserPermissionModel(permission data.Sub, counter int) resources.UserPerm
 			Type:     permission.Type,
 			Link:     permission.Link,
 			AccessLevel: resources.AccessLevel{
-				Name:  data.Roles[permission.AccessLevel],
+				Name:  data.RoleName(permission.AccessLevel),
 				Value: permission.AccessLevel,
 			},
-			Deployable: permission.HasChild,
-			ExpiresAt:  expiresAt,
+			Deployable:       permission.HasChild,
+			ExpiresAt:        expiresAt,
+			CollaboratorKind: permission.CollaboratorKind,
 		},
 	}
 }
@@ -54,4 +55,6 @@ type UserPermissionListResponse struct {
 	Meta  Meta                       `json:"meta"`
 	Data  []resources.UserPermission `json:"data"`
 	Links *resources.Links           `json:"links"`
+	// Included contains access inherited from teams, it isn't a part of direct permissions in data
+	Included resources.Included `json:"included"`
 }
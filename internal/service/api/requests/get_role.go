@@ -8,6 +8,7 @@ import (
 
 type GetRoleRequest struct {
 	AccessLevel *string `filter:"accessLevel"`
+	Link        *string `filter:"link"`
 }
 
 func NewGetRoleRequest(r *http.Request) (GetRoleRequest, error) {
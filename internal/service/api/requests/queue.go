@@ -0,0 +1,18 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+func RetrieveQueue(r *http.Request) (string, error) {
+	queue := chi.URLParam(r, "queue")
+
+	if queue == "" {
+		return "", errors.New("`queue` param is not specified")
+	}
+
+	return queue, nil
+}
@@ -0,0 +1,25 @@
+package requests
+
+import "net/http"
+
+type QueueItemRequest struct {
+	Queue string
+	Id    int64
+}
+
+func NewQueueItemRequest(r *http.Request) (QueueItemRequest, error) {
+	queue, err := RetrieveQueue(r)
+	if err != nil {
+		return QueueItemRequest{}, err
+	}
+
+	id, err := RetrieveId(r)
+	if err != nil {
+		return QueueItemRequest{}, err
+	}
+
+	return QueueItemRequest{
+		Queue: queue,
+		Id:    id,
+	}, nil
+}
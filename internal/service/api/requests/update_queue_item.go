@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"github.com/acs-dl/github-module-svc/resources"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type UpdateQueueItemRequest struct {
+	Queue string
+	Id    int64
+	Data  resources.QueueItemPriority `json:"data"`
+}
+
+func NewUpdateQueueItemRequest(r *http.Request) (UpdateQueueItemRequest, error) {
+	var request UpdateQueueItemRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return request, errors.Wrap(err, " failed to unmarshal")
+	}
+
+	queue, err := RetrieveQueue(r)
+	if err != nil {
+		return request, err
+	}
+	request.Queue = queue
+
+	id, err := RetrieveId(r)
+	if err != nil {
+		return request, err
+	}
+	request.Id = id
+
+	return request, request.validate()
+}
+
+func (r *UpdateQueueItemRequest) validate() error {
+	return validation.Errors{
+		"priority": validation.Validate(&r.Data.Attributes.Priority,
+			validation.In(int64(pqueue.HighPriority), int64(pqueue.NormalPriority), int64(pqueue.LowPriority))),
+	}.Filter()
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/acs-dl/github-module-svc/internal/service/api/handlers"
 	"github.com/acs-dl/github-module-svc/internal/service/background"
 	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
 	"gitlab.com/distributed_lab/ape"
 )
 
@@ -22,6 +23,8 @@ func (r *Router) apiRouter() chi.Router {
 	router.Use(
 		ape.RecoverMiddleware(logger),
 		ape.LoganMiddleware(logger),
+		//handlers wait for queued GitHub calls, deadline drops them when client can't wait anymore
+		middleware.Timeout(r.cfg.Deadlines().Api),
 		ape.CtxMiddleware(
 			//base
 			background.CtxLog(logger),
@@ -31,49 +34,92 @@ func (r *Router) apiRouter() chi.Router {
 			background.CtxUsersQ(postgres.NewUsersQ(r.cfg.DB())),
 			background.CtxLinksQ(postgres.NewLinksQ(r.cfg.DB())),
 			background.CtxSubsQ(postgres.NewSubsQ(r.cfg.DB())),
+			background.CtxTeamPermissionsQ(postgres.NewTeamPermissionsQ(r.cfg.DB())),
+			background.CtxCustomRolesQ(postgres.NewCustomRolesQ(r.cfg.DB())),
 
 			// other configs
 			background.CtxParentContext(r.parentContext),
 		),
 	)
 
+	readRoles := roleNames(data.RolesAtLeast(data.Repository, data.RoleRead), data.RolesAtLeast(data.Organization, data.RoleMember))
+	writeRoles := roleNames(data.RolesAtLeast(data.Repository, data.RoleWrite), data.RolesAtLeast(data.Organization, data.RoleMember))
+	//submodule role names are ambiguous, e.g. `Admin` of repository, so queues are operated by module owners only
+	ownerRoles := []string{data.ModuleOwnerName}
+
 	router.Route("/integrations/github", func(r chi.Router) {
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 			Get("/get_input", handlers.GetInputs)
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 			Get("/get_available_roles", handlers.GetRoles)
 
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, writeRoles...)).
 			Route("/links", func(r chi.Router) {
 				r.Post("/", handlers.AddLink)
 				r.Delete("/", handlers.RemoveLink)
 			})
 
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 			Get("/permissions", handlers.GetPermissions)
 
 		r.Get("/role", handlers.GetRole)               // comes from orchestrator
 		r.Get("/roles", handlers.GetRolesMap)          // comes from orchestrator
 		r.Get("/user_roles", handlers.GetUserRolesMap) // comes from orchestrator
 
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 			Route("/estimate_refresh", func(r chi.Router) {
 				r.Post("/submodule", handlers.GetEstimatedRefreshSubmodule)
 				r.Post("/module", handlers.GetEstimatedRefreshModule)
 			})
 
-		r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+		r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 			Get("/submodule", handlers.CheckSubmodule)
 
+		r.Route("/queues", func(r chi.Router) {
+			r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
+				Get("/wait_times", handlers.GetQueueWaitTimes)
+
+			//operators inspect and control queues during incidents
+			r.Group(func(r chi.Router) {
+				r.Use(auth.Jwt(secret, data.ModuleName, ownerRoles...))
+
+				r.Get("/", handlers.GetQueues)
+				r.Get("/{queue}/items", handlers.GetQueueItems)
+				r.Patch("/{queue}/items/{id}", handlers.UpdateQueueItem)
+				r.Delete("/{queue}/items/{id}", handlers.CancelQueueItem)
+				r.Post("/{queue}/pause", handlers.PauseQueue)
+				r.Post("/{queue}/resume", handlers.ResumeQueue)
+			})
+		})
+
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/{id}", handlers.GetUserById) // comes from orchestrator
 
-			r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+			r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 				Get("/", handlers.GetUsers)
-			r.With(auth.Jwt(secret, data.ModuleName, []string{data.Roles["read"], data.Roles["triage"], data.Roles["write"], data.Roles["maintain"], data.Roles["admin"], data.Roles["member"]}...)).
+			r.With(auth.Jwt(secret, data.ModuleName, readRoles...)).
 				Get("/unverified", handlers.GetUnverifiedUsers)
 		})
 	})
 
 	return router
 }
+
+// roleNames returns unique names of roles as jwt contains them
+func roleNames(scopes ...[]data.Role) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, roles := range scopes {
+		for _, role := range roles {
+			if seen[role.Name] {
+				continue
+			}
+
+			seen[role.Name] = true
+			result = append(result, role.Name)
+		}
+	}
+
+	return result
+}
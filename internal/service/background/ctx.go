@@ -17,6 +17,8 @@ const (
 	usersCtxKey
 	linksCtxKey
 	subsCtxKey
+	teamPermissionsCtxKey
+	customRolesCtxKey
 	PqueueCtxKey
 	GithubClientCtxKey
 	parentContextCtxKey
@@ -73,6 +75,26 @@ func CtxSubsQ(entry data.Subs) func(context.Context) context.Context {
 	}
 }
 
+func TeamPermissionsQ(r *http.Request) data.TeamPermissions {
+	return r.Context().Value(teamPermissionsCtxKey).(data.TeamPermissions).New()
+}
+
+func CtxTeamPermissionsQ(entry data.TeamPermissions) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, teamPermissionsCtxKey, entry)
+	}
+}
+
+func CustomRolesQ(r *http.Request) data.CustomRoles {
+	return r.Context().Value(customRolesCtxKey).(data.CustomRoles).New()
+}
+
+func CtxCustomRolesQ(entry data.CustomRoles) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, customRolesCtxKey, entry)
+	}
+}
+
 func Config(ctx context.Context) config.Config {
 	return ctx.Value(configCtxKey).(config.Config)
 }
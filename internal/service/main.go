@@ -42,9 +42,8 @@ func Run(cfg config.Config) {
 	logger.Info("Starting all available services...")
 
 	stopProcessQueue := make(chan struct{})
-	pqueues := pqueue.NewPQueues()
-	go pqueues.SuperUserPQueue.ProcessQueue(cfg.RateLimit().RequestsAmount, cfg.RateLimit().TimeLimit, stopProcessQueue)
-	go pqueues.UserPQueue.ProcessQueue(cfg.RateLimit().RequestsAmount, cfg.RateLimit().TimeLimit, stopProcessQueue)
+	pqueues := pqueue.NewPQueues(defaultRateLimit(cfg), ownersRateLimits(cfg))
+	pqueues.ProcessQueues(stopProcessQueue)
 	ctx = pqueue.CtxPQueues(&pqueues, ctx)
 	ctx = background.CtxConfig(cfg, ctx)
 
@@ -75,3 +74,64 @@ func Run(cfg config.Config) {
 
 	wg.Wait()
 }
+
+func newRateLimit(cfg *config.RateLimitCfg) pqueue.RateLimit {
+	return pqueue.RateLimit{
+		RequestsAmount: cfg.RequestsAmount,
+		TimeLimit:      cfg.TimeLimit,
+		Backoff: pqueue.Backoff{
+			MaxRetries: cfg.MaxRetries,
+			Initial:    cfg.BackoffInitial,
+			Max:        cfg.BackoffMax,
+		},
+		HighPriorityReserve: cfg.HighPriorityReserve,
+		Resources:           newResourceLimits(cfg.Resources),
+		Aging:               cfg.PriorityAging,
+		Shares: map[int]float64{
+			pqueue.HighPriority:   cfg.HighPriorityShare,
+			pqueue.NormalPriority: cfg.NormalPriorityShare,
+			pqueue.LowPriority:    cfg.LowPriorityShare,
+		},
+	}
+}
+
+// defaultRateLimit returns rate limit of default credentials, GitHub App installation is one budget for reads and writes
+func defaultRateLimit(cfg config.Config) pqueue.RateLimit {
+	rateLimit := newRateLimit(cfg.RateLimit())
+	rateLimit.SharedBudget = cfg.Github().App != nil
+
+	return rateLimit
+}
+
+func newResourceLimits(resources map[string]config.ResourceRateLimitCfg) map[string]pqueue.ResourceLimit {
+	result := make(map[string]pqueue.ResourceLimit)
+	for resource, resourceCfg := range resources {
+		result[resource] = pqueue.ResourceLimit{
+			RequestsAmount: resourceCfg.RequestsAmount,
+			TimeLimit:      resourceCfg.TimeLimit,
+		}
+	}
+
+	return result
+}
+
+// ownersRateLimits returns rate limit for every owner with own credentials,
+// owners without explicit limits get the same budget as default credentials
+func ownersRateLimits(cfg config.Config) map[string]pqueue.RateLimit {
+	result := make(map[string]pqueue.RateLimit)
+
+	for owner, ownerCfg := range cfg.Github().Owners {
+		rateLimit := newRateLimit(cfg.RateLimit())
+		if ownerCfg.RequestsAmount != 0 {
+			rateLimit.RequestsAmount = ownerCfg.RequestsAmount
+		}
+		if ownerCfg.TimeLimit != 0 {
+			rateLimit.TimeLimit = ownerCfg.TimeLimit
+		}
+		rateLimit.SharedBudget = ownerCfg.UsesApp()
+
+		result[owner] = rateLimit
+	}
+
+	return result
+}
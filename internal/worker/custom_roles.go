@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (w *Worker) processCustomRoles(ctx context.Context, link string) error {
+	w.logger.Debugf("processing custom roles for link `%s`", link)
+
+	startTime := time.Now()
+
+	roles, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("GetCustomRepositoryRolesFromApi", link),
+		func(ctx context.Context) ([]data.CustomRole, error) {
+			return w.githubClient.GetCustomRepositoryRolesFromApi(ctx, link)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to get custom roles for link `%s`", link))
+	}
+
+	for _, role := range roles {
+		err = w.customRolesQ.Upsert(role)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to upsert custom role `%s`", role.Name))
+		}
+	}
+
+	//roles that weren't returned were deleted in organization
+	oldRoles, err := w.customRolesQ.FilterByLinks(link).FilterByLowerTime(startTime).Select()
+	if err != nil {
+		return errors.Wrap(err, "failed to select old custom roles")
+	}
+
+	if len(oldRoles) == 0 {
+		return nil
+	}
+
+	return w.customRolesQ.FilterByLinks(link).FilterByLowerTime(startTime).Delete()
+}
@@ -0,0 +1,254 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acs-dl/github-module-svc/internal/data"
+	"github.com/acs-dl/github-module-svc/internal/pqueue"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// processInvitations moves pending invitations to the state they have on GitHub,
+// it must run after links are processed, so accepted users already have permissions
+func (w *Worker) processInvitations(ctx context.Context) error {
+	w.logger.Infof("started processing invitations")
+
+	invitations, err := w.invitationsQ.FilterByStates(data.InvitationPending).Select()
+	if err != nil {
+		w.logger.Infof("failed to select invitations")
+		return errors.Wrap(err, "failed to select invitations")
+	}
+
+	w.logger.Infof("found `%d` pending invitations", len(invitations))
+
+	byLink := make(map[string][]data.Invitation)
+	for _, invitation := range invitations {
+		byLink[invitation.Link] = append(byLink[invitation.Link], invitation)
+	}
+
+	for link, linkInvitations := range byLink {
+		apiInvitations, err := pqueue.Submit(
+			ctx,
+			w.pqueues.ForLink(link).UserPQueue,
+			pqueue.NewKey("GetInvitationsFromApi", link, linkInvitations[0].Type),
+			func(ctx context.Context) ([]data.Invitation, error) {
+				return w.githubClient.GetInvitationsFromApi(ctx, link, linkInvitations[0].Type)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			w.logger.Infof("failed to get invitations for link `%s`", link)
+			return errors.Wrap(err, "failed to get invitations from api")
+		}
+
+		for _, invitation := range linkInvitations {
+			apiInvitation := findInvitation(apiInvitations, invitation)
+			if apiInvitation != nil {
+				err = w.refreshInvitation(ctx, invitation, *apiInvitation)
+			} else {
+				err = w.completeInvitation(ctx, invitation)
+			}
+			if err != nil {
+				w.logger.Infof("failed to process invitation with id `%d` to `%s`", invitation.Id, link)
+				return errors.Wrap(err, "failed to process invitation")
+			}
+		}
+	}
+
+	w.logger.Infof("finished processing invitations")
+	return nil
+}
+
+func findInvitation(apiInvitations []data.Invitation, invitation data.Invitation) *data.Invitation {
+	for i, apiInvitation := range apiInvitations {
+		switch {
+		case invitation.InvitationId != nil && apiInvitation.InvitationId != nil:
+			if *invitation.InvitationId == *apiInvitation.InvitationId {
+				return &apiInvitations[i]
+			}
+		case invitation.Username != "":
+			if strings.EqualFold(invitation.Username, apiInvitation.Username) {
+				return &apiInvitations[i]
+			}
+		case invitation.Email != nil && apiInvitation.Email != nil:
+			if strings.EqualFold(*invitation.Email, *apiInvitation.Email) {
+				return &apiInvitations[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// refreshInvitation handles invitation that is still on GitHub, so it's either pending or expired
+func (w *Worker) refreshInvitation(ctx context.Context, invitation, apiInvitation data.Invitation) error {
+	if apiInvitation.State == data.InvitationExpired && w.resendExpired {
+		return w.resendInvitation(ctx, invitation)
+	}
+
+	toUpdate := data.InvitationToUpdate{
+		InvitationId: apiInvitation.InvitationId,
+		State:        &apiInvitation.State,
+	}
+
+	//GitHub finds account for email invitation once invitee has one
+	if invitation.Username == "" && apiInvitation.Username != "" {
+		user, err := pqueue.Submit(
+			ctx,
+			w.pqueues.UserPQueue,
+			pqueue.NewKey("GetUserFromApi", apiInvitation.Username),
+			func(ctx context.Context) (*data.User, error) {
+				return w.githubClient.GetUserFromApi(ctx, apiInvitation.Username)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to get user from api")
+		}
+
+		if user != nil {
+			toUpdate.Username = &user.Username
+			toUpdate.GithubId = &user.GithubId
+		}
+	}
+
+	return w.invitationsQ.FilterByIds(invitation.Id).Update(toUpdate)
+}
+
+// completeInvitation handles invitation that has gone from GitHub: user either accepted or declined it
+func (w *Worker) completeInvitation(ctx context.Context, invitation data.Invitation) error {
+	//account is linked only when GitHub reports invitee login, new members are never guessed
+	if invitation.Username == "" {
+		w.logger.Warnf("GitHub didn't report invitee of invitation with id `%d`, its state is unknown", invitation.Id)
+
+		state := data.InvitationUnknown
+		return w.invitationsQ.FilterByIds(invitation.Id).Update(data.InvitationToUpdate{
+			State: &state,
+		})
+	}
+
+	permission, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(invitation.Link).UserPQueue,
+		pqueue.NewKey("CheckUserFromApi", invitation.Link, invitation.Username, invitation.Type),
+		func(ctx context.Context) (*data.Permission, error) {
+			return w.githubClient.CheckUserFromApi(ctx, invitation.Link, invitation.Username, invitation.Type)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to check user from api")
+	}
+
+	state := data.InvitationCancelled
+	if permission != nil {
+		//organization roles can be assigned to members only
+		if invitation.Type == data.Organization && data.IsOrganizationRole(invitation.AccessLevel) && permission.AccessLevel != invitation.AccessLevel {
+			err = w.assignOrganizationRole(ctx, invitation)
+			if err != nil {
+				return errors.Wrap(err, "failed to assign organization role")
+			}
+		}
+
+		err = w.acceptInvitation(ctx, invitation)
+		if err != nil {
+			return errors.Wrap(err, "failed to accept invitation")
+		}
+
+		state = data.InvitationAccepted
+	}
+
+	return w.invitationsQ.FilterByIds(invitation.Id).Update(data.InvitationToUpdate{
+		Username: &invitation.Username,
+		State:    &state,
+	})
+}
+
+// acceptInvitation links invitee with identity user through the usual verify flow
+func (w *Worker) acceptInvitation(ctx context.Context, invitation data.Invitation) error {
+	if invitation.UserId == nil {
+		return nil
+	}
+
+	return w.processor.HandleVerifyUserAction(ctx, data.ModulePayload{
+		RequestId: invitation.RequestId,
+		UserId:    strconv.FormatInt(*invitation.UserId, 10),
+		Username:  invitation.Username,
+	})
+}
+
+func (w *Worker) assignOrganizationRole(ctx context.Context, invitation data.Invitation) error {
+	permission, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(invitation.Link).SuperUserPQueue,
+		pqueue.NewKey("AssignOrganizationRoleFromApi", invitation.Link, invitation.Username, invitation.AccessLevel),
+		func(ctx context.Context) (*data.Permission, error) {
+			return w.githubClient.AssignOrganizationRoleFromApi(ctx, invitation.Link, invitation.Username, invitation.AccessLevel)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to assign organization role from api")
+	}
+	if permission == nil {
+		return errors.New("something wrong with assigning organization role")
+	}
+
+	return nil
+}
+
+func (w *Worker) resendInvitation(ctx context.Context, invitation data.Invitation) error {
+	w.logger.Infof("resending expired invitation with id `%d` to `%s`", invitation.Id, invitation.Link)
+
+	invitation.CreatedAt = time.Now()
+
+	if invitation.Username == "" && invitation.Email != nil {
+		resent, err := pqueue.Submit(
+			ctx,
+			w.pqueues.ForLink(invitation.Link).SuperUserPQueue,
+			pqueue.NewKey("InviteToOrganizationByEmailFromApi", invitation.Link, *invitation.Email, invitation.AccessLevel),
+			func(ctx context.Context) (*data.Invitation, error) {
+				return w.githubClient.InviteToOrganizationByEmailFromApi(ctx, invitation.Link, *invitation.Email, invitation.AccessLevel)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to invite user from api")
+		}
+		if resent == nil {
+			return errors.New("something wrong with inviting user")
+		}
+
+		invitation.InvitationId = resent.InvitationId
+		invitation.State = resent.State
+
+		return w.invitationsQ.Upsert(invitation)
+	}
+
+	permission, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(invitation.Link).SuperUserPQueue,
+		pqueue.NewKey("AddUserFromApi", invitation.Type, invitation.Link, invitation.Username, invitation.AccessLevel),
+		func(ctx context.Context) (*data.Permission, error) {
+			return w.githubClient.AddUserFromApi(ctx, invitation.Type, invitation.Link, invitation.Username, invitation.AccessLevel)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to add user from api")
+	}
+	if permission == nil {
+		return errors.New("something wrong with adding user")
+	}
+
+	invitation.State = data.InvitationAccepted
+	if permission.Invitation != nil {
+		invitation.State = permission.Invitation.State
+		invitation.InvitationId = permission.Invitation.InvitationId
+	}
+
+	return w.invitationsQ.Upsert(invitation)
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"fmt"
 	"gitlab.com/distributed_lab/logan/v3"
+	"strings"
 	"time"
 
 	"github.com/acs-dl/github-module-svc/internal/config"
 	"github.com/acs-dl/github-module-svc/internal/data"
 	"github.com/acs-dl/github-module-svc/internal/data/postgres"
 	"github.com/acs-dl/github-module-svc/internal/github"
-	"github.com/acs-dl/github-module-svc/internal/helpers"
 	"github.com/acs-dl/github-module-svc/internal/pqueue"
 	"github.com/acs-dl/github-module-svc/internal/processor"
 	"github.com/google/uuid"
@@ -20,10 +20,16 @@ import (
 
 const ServiceName = data.ModuleName + "-worker"
 
+// cachedResponseTTL is how long cached response lives without being used, e.g. the one made with rotated token
+const cachedResponseTTL = 24 * time.Hour
+
+// finishedJobTTL is how long done and failed jobs are kept for investigation
+const finishedJobTTL = 7 * 24 * time.Hour
+
 type IWorker interface {
 	Run(ctx context.Context)
 	ProcessPermissions(ctx context.Context) error
-	RefreshSubmodules(msg data.ModulePayload) error
+	RefreshSubmodules(ctx context.Context, msg data.ModulePayload) error
 	GetEstimatedTime() time.Duration
 }
 
@@ -35,9 +41,15 @@ type Worker struct {
 	usersQ        data.Users
 	subsQ         data.Subs
 	permissionsQ  data.Permissions
+	teamPermsQ    data.TeamPermissions
+	invitationsQ  data.Invitations
+	customRolesQ  data.CustomRoles
+	httpCacheQ    data.HttpCache
+	jobsQ         data.Jobs
 	pqueues       *pqueue.PQueues
 	runnerDelay   time.Duration
 	estimatedTime time.Duration
+	resendExpired bool
 }
 
 func NewWorkerAsInterface(cfg config.Config, ctx context.Context) interface{} {
@@ -50,12 +62,22 @@ func NewWorkerAsInterface(cfg config.Config, ctx context.Context) interface{} {
 		subsQ:         postgres.NewSubsQ(cfg.DB()),
 		usersQ:        postgres.NewUsersQ(cfg.DB()),
 		permissionsQ:  postgres.NewPermissionsQ(cfg.DB()),
+		teamPermsQ:    postgres.NewTeamPermissionsQ(cfg.DB()),
+		invitationsQ:  postgres.NewInvitationsQ(cfg.DB()),
+		customRolesQ:  postgres.NewCustomRolesQ(cfg.DB()),
+		httpCacheQ:    postgres.NewHttpCacheQ(cfg.DB()),
+		jobsQ:         postgres.NewJobsQ(cfg.DB()),
 		estimatedTime: time.Duration(0),
 		runnerDelay:   cfg.Runners().Worker,
+		resendExpired: cfg.Invitations().ResendExpired,
 	})
 }
 
 func (w *Worker) Run(ctx context.Context) {
+	if err := w.githubClient.ObserveRateLimitsFromApi(ctx); err != nil {
+		w.logger.WithError(err).Warn("failed to get rate limits, queues are paced by config until first response")
+	}
+
 	running.WithBackOff(
 		ctx,
 		w.logger,
@@ -67,7 +89,7 @@ func (w *Worker) Run(ctx context.Context) {
 	)
 }
 
-func (w *Worker) ProcessPermissions(_ context.Context) error {
+func (w *Worker) ProcessPermissions(ctx context.Context) error {
 	w.logger.Info("fetching links")
 
 	startTime := time.Now()
@@ -88,7 +110,7 @@ func (w *Worker) ProcessPermissions(_ context.Context) error {
 	for _, link := range links {
 		w.logger.Infof("processing link `%s`", link.Link)
 
-		err = w.createSubs(link.Link)
+		err = w.createSubs(ctx, link.Link)
 		if err != nil {
 			w.logger.Infof("failed to create subs for link `%s", link.Link)
 			return errors.Wrap(err, "failed to create subs")
@@ -98,6 +120,12 @@ func (w *Worker) ProcessPermissions(_ context.Context) error {
 
 	}
 
+	err = w.processInvitations(ctx)
+	if err != nil {
+		w.logger.WithError(err).Errorf("failed to process invitations")
+		return errors.Wrap(err, "failed to process invitations")
+	}
+
 	err = w.removeOldUsers(startTime)
 	if err != nil {
 		w.logger.WithError(err).Errorf("failed to remove old users")
@@ -110,16 +138,34 @@ func (w *Worker) ProcessPermissions(_ context.Context) error {
 		return errors.Wrap(err, "failed to remove old permissions")
 	}
 
+	err = w.removeOldTeamPermissions(startTime)
+	if err != nil {
+		w.logger.WithError(err).Errorf("failed to remove old team permissions")
+		return errors.Wrap(err, "failed to remove old team permissions")
+	}
+
+	err = w.removeOldCachedResponses()
+	if err != nil {
+		w.logger.WithError(err).Errorf("failed to remove old cached responses")
+		return errors.Wrap(err, "failed to remove old cached responses")
+	}
+
+	err = w.removeOldJobs()
+	if err != nil {
+		w.logger.WithError(err).Errorf("failed to remove old jobs")
+		return errors.Wrap(err, "failed to remove old jobs")
+	}
+
 	w.estimatedTime = time.Now().Sub(startTime)
 	return nil
 }
 
-func (w *Worker) RefreshSubmodules(msg data.ModulePayload) error {
+func (w *Worker) RefreshSubmodules(ctx context.Context, msg data.ModulePayload) error {
 	w.logger.Infof("started refresh submodules")
 
 	for _, link := range msg.Links {
 		w.logger.Infof("started refreshing `%s`", link)
-		err := w.createSubs(link)
+		err := w.createSubs(ctx, link)
 		if err != nil {
 			w.logger.Infof("failed to create subs for link `%s", link)
 			return errors.Wrap(err, "failed to create subs")
@@ -185,10 +231,62 @@ func (w *Worker) removeOldPermissions(borderTime time.Time) error {
 	return nil
 }
 
-func (w *Worker) createPermission(link string) error {
+func (w *Worker) removeOldTeamPermissions(borderTime time.Time) error {
+	w.logger.Infof("started removing old team permissions")
+
+	permissions, err := w.teamPermsQ.FilterByLowerTime(borderTime).Select()
+	if err != nil {
+		w.logger.Infof("failed to select team permissions")
+		return errors.Wrap(err, " failed to select team permissions")
+	}
+
+	w.logger.Infof("found `%d` team permissions to delete", len(permissions))
+
+	for _, permission := range permissions {
+		err = w.teamPermsQ.FilterByTeamLinks(permission.TeamLink).FilterByRepoLinks(permission.RepoLink).Delete()
+		if err != nil {
+			w.logger.Infof("failed to delete team permission")
+			return errors.Wrap(err, " failed to delete team permission")
+		}
+	}
+
+	w.logger.Infof("finished removing old team permissions")
+	return nil
+}
+
+func (w *Worker) removeOldCachedResponses() error {
+	w.logger.Infof("started removing old cached responses")
+
+	err := w.httpCacheQ.FilterByLowerTime(time.Now().Add(-cachedResponseTTL)).Delete()
+	if err != nil {
+		w.logger.Infof("failed to delete cached responses")
+		return errors.Wrap(err, " failed to delete cached responses")
+	}
+
+	w.logger.Infof("finished removing old cached responses")
+	return nil
+}
+
+func (w *Worker) removeOldJobs() error {
+	w.logger.Infof("started removing old jobs")
+
+	err := w.jobsQ.New().
+		FilterByStatuses(data.JobDone, data.JobFailed).
+		FilterByLowerTime(time.Now().Add(-finishedJobTTL)).
+		Delete()
+	if err != nil {
+		w.logger.Infof("failed to delete jobs")
+		return errors.Wrap(err, "failed to delete jobs")
+	}
+
+	w.logger.Infof("finished removing old jobs")
+	return nil
+}
+
+func (w *Worker) createPermission(ctx context.Context, link string) error {
 	w.logger.Infof("processing sub `%s`", link)
 
-	if err := w.processor.HandleGetUsersAction(data.ModulePayload{
+	if err := w.processor.HandleGetUsersAction(ctx, data.ModulePayload{
 		RequestId: "from-worker",
 		Link:      link,
 	}); err != nil {
@@ -201,24 +299,22 @@ func (w *Worker) createPermission(link string) error {
 	return nil
 }
 
-func (w *Worker) createSubs(link string) error {
+func (w *Worker) createSubs(ctx context.Context, link string) error {
 	w.logger.Infof("creating subs for link `%s", link)
 
-	item, err := helpers.AddFunctionInPQueue(w.pqueues.SuperUserPQueue, any(w.githubClient.FindType), []any{any(link)}, pqueue.LowPriority)
-	if err != nil {
-		w.logger.WithError(err).Errorf("failed to add function in pqueue")
-		return errors.Wrap(err, "failed to add function in pqueue")
-	}
-
-	err = item.Response.Error
+	typeSub, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("FindType", link),
+		func(ctx context.Context) (*github.TypeSub, error) {
+			return w.githubClient.FindType(ctx, link)
+		},
+		pqueue.LowPriority,
+	)
 	if err != nil {
 		w.logger.WithError(err).Errorf("failed to get type")
 		return errors.Wrap(err, "failed to get type")
 	}
-	typeSub, ok := item.Response.Value.(*github.TypeSub)
-	if !ok {
-		return errors.Errorf("wrong response type")
-	}
 
 	if typeSub == nil {
 		w.logger.Infof("failed to get sub for link `%s`", link)
@@ -237,17 +333,30 @@ func (w *Worker) createSubs(link string) error {
 		return errors.Wrap(err, "failed to upsert sub")
 	}
 
-	err = w.createPermission(typeSub.Sub.Link)
+	err = w.createPermission(ctx, typeSub.Sub.Link)
 	if err != nil {
 		w.logger.Infof("failed to create permissions for sub with link `%s`", link)
 		return errors.Wrap(err, "failed to create permissions for sub")
 	}
 
-	if typeSub.Type == data.Repository {
+	if typeSub.Type == data.Repository || typeSub.Type == data.Team {
 		return nil
 	}
 
-	err = w.processNested(link, typeSub.Sub.Id)
+	err = w.processCustomRoles(ctx, link)
+	if err != nil {
+		w.logger.Infof("failed to index custom roles for link `%s`", link)
+		return errors.Wrap(err, "failed to index custom roles")
+	}
+
+	//team grants are needed to tell how members got access to repositories
+	err = w.processTeams(ctx, link, typeSub.Sub.Id)
+	if err != nil {
+		w.logger.Infof("failed to index teams for link `%s`", link)
+		return errors.Wrap(err, "failed to index teams")
+	}
+
+	err = w.processNested(ctx, link, typeSub.Sub.Id)
 	if err != nil {
 		w.logger.Infof("failed to index subs for link `%s`", link)
 		return errors.Wrap(err, "failed to index subs")
@@ -257,49 +366,182 @@ func (w *Worker) createSubs(link string) error {
 	return nil
 }
 
-func (w *Worker) processNested(link string, parentId int64) error {
+func (w *Worker) processNested(ctx context.Context, link string, parentId int64) error {
 	w.logger.Debugf("processing link `%s`", link)
 
-	item, err := helpers.AddFunctionInPQueue(w.pqueues.SuperUserPQueue, any(w.githubClient.GetProjectsFromApi), []any{any(link)}, pqueue.LowPriority)
-	if err != nil {
-		w.logger.WithError(err).Errorf("failed to add function in pqueue")
-		return errors.Wrap(err, "failed to add function in pqueue")
+	//every page is queued on its own, so repositories are indexed without keeping them all in memory
+	for pageLink := ""; ; {
+		page, err := pqueue.Submit(
+			ctx,
+			w.pqueues.ForLink(link).UserPQueue,
+			pqueue.NewKey("GetProjectsPageFromApi", link, pageLink),
+			func(ctx context.Context) (data.Page[data.Sub], error) {
+				return w.githubClient.GetProjectsPageFromApi(ctx, link, pageLink)
+			},
+			pqueue.LowPriority,
+		)
+		if err != nil {
+			w.logger.Infof("failed to get projects for link `%s`", link)
+			return errors.Wrap(err, fmt.Sprintf("failed to get projects for link `%s`", link))
+		}
+
+		for _, project := range page.Items {
+			projectLink := link + "/" + project.Path
+
+			err = w.subsQ.Upsert(data.Sub{
+				Id:       project.Id,
+				Path:     project.Path,
+				Link:     projectLink,
+				Type:     data.Repository,
+				ParentId: &parentId,
+			})
+			if err != nil {
+				w.logger.Infof("failed to upsert sub with link `%s`", projectLink)
+				return errors.Wrap(err, fmt.Sprintf("failed to get upsert sub with link `%s`", projectLink))
+			}
+
+			err = w.createPermission(ctx, projectLink)
+			if err != nil {
+				w.logger.Infof("failed to create permissions for sub with link `%s`", projectLink)
+				return errors.Wrap(err, "failed to create permissions for sub")
+			}
+		}
+
+		if page.Next == "" {
+			return nil
+		}
+		pageLink = page.Next
 	}
+}
 
-	err = item.Response.Error
+func (w *Worker) processTeams(ctx context.Context, link string, orgId int64) error {
+	w.logger.Debugf("processing teams for link `%s`", link)
+
+	teams, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(link).UserPQueue,
+		pqueue.NewKey("GetTeamsFromApi", link),
+		func(ctx context.Context) ([]data.Sub, error) {
+			return w.githubClient.GetTeamsFromApi(ctx, link)
+		},
+		pqueue.LowPriority,
+	)
 	if err != nil {
-		w.logger.Infof("failed to get projects for link `%s`", link)
-		return errors.Wrap(err, fmt.Sprintf("failed to get projects for link `%s`", link))
+		w.logger.Infof("failed to get teams for link `%s`", link)
+		return errors.Wrap(err, fmt.Sprintf("failed to get teams for link `%s`", link))
 	}
 
-	projects, ok := item.Response.Value.([]data.Sub)
-	if !ok {
-		return errors.Errorf("wrong response type")
-	}
+	for _, team := range sortTeamsByHierarchy(teams) {
+		//top level teams are nested directly in organization
+		parentId := orgId
+		if team.ParentId != nil {
+			parentId = *team.ParentId
+		}
 
-	for _, project := range projects {
 		err = w.subsQ.Upsert(data.Sub{
-			Id:       project.Id,
-			Path:     project.Path,
-			Link:     link + "/" + project.Path,
-			Type:     data.Repository,
+			Id:       team.Id,
+			Path:     team.Path,
+			Link:     team.Link,
+			Type:     data.Team,
 			ParentId: &parentId,
 		})
 		if err != nil {
-			w.logger.Infof("failed to upsert sub with link `%s`", link+"/"+project.Path)
-			return errors.Wrap(err, fmt.Sprintf("failed to get upsert sub with link `%s`", link+"/"+project.Path))
+			w.logger.Infof("failed to upsert sub with link `%s`", team.Link)
+			return errors.Wrap(err, fmt.Sprintf("failed to get upsert sub with link `%s`", team.Link))
 		}
 
-		err = w.createPermission(link + "/" + project.Path)
+		err = w.createPermission(ctx, team.Link)
 		if err != nil {
-			w.logger.Infof("failed to create permissions for sub with link `%s`", link+"/"+project.Path)
+			w.logger.Infof("failed to create permissions for sub with link `%s`", team.Link)
 			return errors.Wrap(err, "failed to create permissions for sub")
 		}
+
+		err = w.createTeamPermissions(ctx, team.Link)
+		if err != nil {
+			w.logger.Infof("failed to create team permissions for team with link `%s`", team.Link)
+			return errors.Wrap(err, "failed to create team permissions")
+		}
 	}
 
 	return nil
 }
 
+func (w *Worker) createTeamPermissions(ctx context.Context, teamLink string) error {
+	w.logger.Debugf("processing repositories for team `%s`", teamLink)
+
+	permissions, err := pqueue.Submit(
+		ctx,
+		w.pqueues.ForLink(teamLink).UserPQueue,
+		pqueue.NewKey("GetTeamRepositoriesFromApi", teamLink),
+		func(ctx context.Context) ([]data.TeamPermission, error) {
+			return w.githubClient.GetTeamRepositoriesFromApi(ctx, teamLink)
+		},
+		pqueue.LowPriority,
+	)
+	if err != nil {
+		w.logger.Infof("failed to get repositories for team `%s`", teamLink)
+		return errors.Wrap(err, fmt.Sprintf("failed to get repositories for team `%s`", teamLink))
+	}
+
+	for _, permission := range permissions {
+		permission.RepoLink = strings.ToLower(permission.RepoLink)
+
+		err = w.teamPermsQ.Upsert(permission)
+		if err != nil {
+			w.logger.Infof("failed to upsert team permission for repository `%s`", permission.RepoLink)
+			return errors.Wrap(err, "failed to upsert team permission")
+		}
+	}
+
+	return nil
+}
+
+// sortTeamsByHierarchy puts parent teams before their children,
+// so parent permissions are already indexed when children are processed,
+// duplicated teams are dropped and teams of broken hierarchy are put at the end
+func sortTeamsByHierarchy(teams []data.Sub) []data.Sub {
+	unique := make([]data.Sub, 0, len(teams))
+	known := make(map[int64]bool)
+	for _, team := range teams {
+		if known[team.Id] {
+			continue
+		}
+
+		known[team.Id] = true
+		unique = append(unique, team)
+	}
+
+	result := make([]data.Sub, 0, len(unique))
+	added := make(map[int64]bool)
+
+	for progress := true; progress && len(result) != len(unique); {
+		progress = false
+
+		for _, team := range unique {
+			if added[team.Id] {
+				continue
+			}
+
+			if team.ParentId != nil && known[*team.ParentId] && !added[*team.ParentId] {
+				continue
+			}
+
+			result = append(result, team)
+			added[team.Id] = true
+			progress = true
+		}
+	}
+
+	//parents of the rest are never resolved, e.g. teams are nested in each other
+	for _, team := range unique {
+		if !added[team.Id] {
+			result = append(result, team)
+		}
+	}
+
+	return result
+}
+
 func (w *Worker) GetEstimatedTime() time.Duration {
 	return w.estimatedTime
 }
@@ -9,10 +9,12 @@ type AddUser struct {
 	AccessLevel int `json:"access_level"`
 	// action that must be handled in module, must be \"add_user\"
 	Action string `json:"action"`
+	// email to invite user to organization with, when username isn't known yet
+	Email *string `json:"email,omitempty"`
 	// link where module has to add user
 	Link string `json:"link"`
 	// user's id from identity
 	UserId string `json:"user_id"`
-	// user's username from gitlab
-	Username string `json:"username"`
+	// user's username from gitlab, required when email is empty
+	Username *string `json:"username,omitempty"`
 }
@@ -0,0 +1,16 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type CancelInvitation struct {
+	// action that must be handled in module, must be \"cancel_invitation\"
+	Action string `json:"action"`
+	// email user was invited with
+	Email *string `json:"email,omitempty"`
+	// link where user was invited
+	Link string `json:"link"`
+	// invited user's username from github, required when email is empty
+	Username *string `json:"username,omitempty"`
+}
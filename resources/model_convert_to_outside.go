@@ -0,0 +1,14 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ConvertToOutside struct {
+	// action that must be handled in module, must be \"convert_to_outside\"
+	Action string `json:"action"`
+	// organization where member has to become outside collaborator
+	Link string `json:"link"`
+	// member's username from github
+	Username string `json:"username"`
+}
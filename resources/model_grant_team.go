@@ -0,0 +1,16 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type GrantTeam struct {
+	// level of access for team in repo
+	AccessLevel string `json:"access_level"`
+	// action that must be handled in module, must be \"grant_team\"
+	Action string `json:"action"`
+	// repo where module has to grant access for team
+	Link string `json:"link"`
+	// full path to team from the same organization
+	Team string `json:"team"`
+}
@@ -0,0 +1,18 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type InviteOutsideCollaborator struct {
+	// role in organization, member by default
+	AccessLevel *string `json:"access_level,omitempty"`
+	// action that must be handled in module, must be \"invite_outside_collaborator\"
+	Action string `json:"action"`
+	// organization where outside collaborator has to be invited
+	Link string `json:"link"`
+	// user's id from identity
+	UserId string `json:"user_id"`
+	// outside collaborator's username from github
+	Username string `json:"username"`
+}
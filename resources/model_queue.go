@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type Queue struct {
+	Key
+	Attributes QueueAttributes `json:"attributes"`
+}
+type QueueResponse struct {
+	Data     Queue    `json:"data"`
+	Included Included `json:"included"`
+}
+
+type QueueListResponse struct {
+	Data     []Queue  `json:"data"`
+	Included Included `json:"included"`
+	Links    *Links   `json:"links"`
+}
+
+// MustQueue - returns Queue from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustQueue(key Key) *Queue {
+	var queue Queue
+	if c.tryFindEntry(key, &queue) {
+		return &queue
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueAttributes struct {
+	// amount of calls waiting in queue
+	Length int64 `json:"length"`
+	// paused queue doesn't make calls until it is resumed
+	Paused bool `json:"paused"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueItem struct {
+	Key
+	Attributes QueueItemAttributes `json:"attributes"`
+}
+type QueueItemResponse struct {
+	Data     QueueItem `json:"data"`
+	Included Included  `json:"included"`
+}
+
+type QueueItemListResponse struct {
+	Data     []QueueItem `json:"data"`
+	Included Included    `json:"included"`
+	Links    *Links      `json:"links"`
+}
+
+// MustQueueItem - returns QueueItem from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustQueueItem(key Key) *QueueItem {
+	var queueItem QueueItem
+	if c.tryFindEntry(key, &queueItem) {
+		return &queueItem
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type QueueItemAttributes struct {
+	// amount of producers waiting for call
+	Amount int64    `json:"amount"`
+	Args   []string `json:"args"`
+	// priority class of call
+	Class      string    `json:"class"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// GitHub client method that is called
+	Method string `json:"method"`
+	// place of call in order of aged priorities starting from 1
+	Position int64 `json:"position"`
+	Priority int64 `json:"priority"`
+	// name of queue, owner queues are prefixed with owner
+	Queue string `json:"queue"`
+	// rate limit resource that call spends
+	Resource string `json:"resource"`
+}
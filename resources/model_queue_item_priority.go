@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueItemPriority struct {
+	Key
+	Attributes QueueItemPriorityAttributes `json:"attributes"`
+}
+type QueueItemPriorityResponse struct {
+	Data     QueueItemPriority `json:"data"`
+	Included Included          `json:"included"`
+}
+
+type QueueItemPriorityListResponse struct {
+	Data     []QueueItemPriority `json:"data"`
+	Included Included            `json:"included"`
+	Links    *Links              `json:"links"`
+}
+
+// MustQueueItemPriority - returns QueueItemPriority from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustQueueItemPriority(key Key) *QueueItemPriority {
+	var queueItemPriority QueueItemPriority
+	if c.tryFindEntry(key, &queueItemPriority) {
+		return &queueItemPriority
+	}
+	return nil
+}
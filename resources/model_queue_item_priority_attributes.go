@@ -0,0 +1,10 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueItemPriorityAttributes struct {
+	// new priority of call, from 0 (low) to 10 (high)
+	Priority int64 `json:"priority"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueWaitTime struct {
+	Key
+	Attributes QueueWaitTimeAttributes `json:"attributes"`
+}
+type QueueWaitTimeResponse struct {
+	Data     QueueWaitTime `json:"data"`
+	Included Included      `json:"included"`
+}
+
+type QueueWaitTimeListResponse struct {
+	Data     []QueueWaitTime `json:"data"`
+	Included Included        `json:"included"`
+	Links    *Links          `json:"links"`
+}
+
+// MustQueueWaitTime - returns QueueWaitTime from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustQueueWaitTime(key Key) *QueueWaitTime {
+	var queueWaitTime QueueWaitTime
+	if c.tryFindEntry(key, &queueWaitTime) {
+		return &queueWaitTime
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type QueueWaitTimeAttributes struct {
+	// cumulative histogram of wait time
+	Buckets []WaitTimeBucket `json:"buckets"`
+	// priority class of queued calls
+	Class string `json:"class"`
+	// amount of calls made
+	Count    int64 `json:"count"`
+	Priority int64 `json:"priority"`
+	// total time calls waited in queues
+	Sum string `json:"sum"`
+}
@@ -8,13 +8,18 @@ type ResourceType string
 
 // List of ResourceType
 const (
-	ESTIMATED_TIME  ResourceType = "estimated_time"
-	INPUTS          ResourceType = "inputs"
-	LINKS           ResourceType = "links"
-	MODULES         ResourceType = "modules"
-	REQUESTS        ResourceType = "requests"
-	ROLE            ResourceType = "role"
-	ROLES           ResourceType = "roles"
-	USER            ResourceType = "user"
-	USER_PERMISSION ResourceType = "user_permission"
+	ESTIMATED_TIME      ResourceType = "estimated_time"
+	INPUTS              ResourceType = "inputs"
+	LINKS               ResourceType = "links"
+	MODULES             ResourceType = "modules"
+	QUEUE               ResourceType = "queue"
+	QUEUE_ITEM          ResourceType = "queue_item"
+	QUEUE_ITEM_PRIORITY ResourceType = "queue_item_priority"
+	QUEUE_WAIT_TIME     ResourceType = "queue_wait_time"
+	REQUESTS            ResourceType = "requests"
+	ROLE                ResourceType = "role"
+	ROLES               ResourceType = "roles"
+	TEAM_PERMISSION     ResourceType = "team_permission"
+	USER                ResourceType = "user"
+	USER_PERMISSION     ResourceType = "user_permission"
 )
@@ -0,0 +1,14 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeTeam struct {
+	// action that must be handled in module, must be \"revoke_team\"
+	Action string `json:"action"`
+	// repo where module has to revoke access of team
+	Link string `json:"link"`
+	// full path to team from the same organization
+	Team string `json:"team"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type TeamPermission struct {
+	Key
+	Attributes TeamPermissionAttributes `json:"attributes"`
+}
+type TeamPermissionResponse struct {
+	Data     TeamPermission `json:"data"`
+	Included Included       `json:"included"`
+}
+
+type TeamPermissionListResponse struct {
+	Data     []TeamPermission `json:"data"`
+	Included Included         `json:"included"`
+	Links    *Links           `json:"links"`
+}
+
+// MustTeamPermission - returns TeamPermission from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustTeamPermission(key Key) *TeamPermission {
+	var teamPermission TeamPermission
+	if c.tryFindEntry(key, &teamPermission) {
+		return &teamPermission
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type TeamPermissionAttributes struct {
+	AccessLevel AccessLevel `json:"access_level"`
+	// full path to repo to which team has access
+	Link string `json:"link"`
+	// full path to team which has access
+	Team string `json:"team"`
+}
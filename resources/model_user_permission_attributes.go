@@ -10,6 +10,8 @@ import (
 
 type UserPermissionAttributes struct {
 	AccessLevel AccessLevel `json:"access_level"`
+	// how user got access (member, outside, direct or team)
+	CollaboratorKind string `json:"collaborator_kind"`
 	// indicates whether element have nested object
 	Deployable bool `json:"deployable"`
 	// shows when permission is expired
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type WaitTimeBucket struct {
+	// amount of calls that waited no longer than bound
+	Count int64 `json:"count"`
+	// upper bound of wait time, `+Inf` for the last bucket
+	Le string `json:"le"`
+}